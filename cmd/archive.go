@@ -0,0 +1,441 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// Limits that protect against archives that expand to far more data than
+// they hold, also known as zip bombs.
+const (
+	// maxArchiveEntries is the largest number of entries an archive may have.
+	maxArchiveEntries = 100000
+	// maxArchiveContentSize is the largest uncompressed size of an archive.
+	maxArchiveContentSize = 4 << 30
+	// maxArchiveCompressionRatio is the largest ratio between the uncompressed
+	// and compressed size of an entry larger than archiveRatioMinSize.
+	maxArchiveCompressionRatio = 200
+	archiveRatioMinSize        = 1 << 20
+	// maxArchiveLinkTarget is the longest allowed symbolic link target.
+	maxArchiveLinkTarget = 4096
+)
+
+// errArchiveTooLarge is reported once an archive holds more than
+// maxArchiveContentSize of data.
+var errArchiveTooLarge = fmt.Errorf("archive content exceeds %s", formatByteSize(maxArchiveContentSize))
+
+// Archive formats recognized by sniffArchiveFormat.
+const (
+	archiveFormatZip   = "zip"
+	archiveFormatTar   = "tar"
+	archiveFormatTarGz = "tar.gz"
+)
+
+// prepareArchive turns a prebuilt archive into a bundle that can be uploaded.
+// source is the path of a zip, tar or tar.gz archive, or "-" to read it from
+// stdin. Zip archives are uploaded as they are, while tar archives are
+// converted to zip. The bundle is validated before it is returned, along with
+// its entries and a function that removes any temporary file it created.
+func prepareArchive(source string, stdin io.Reader) (string, []bundleEntry, func(), error) {
+	var temporary []string
+	cleanup := func() {
+		for _, p := range temporary {
+			os.Remove(p)
+		}
+	}
+	fail := func(err error) (string, []bundleEntry, func(), error) {
+		cleanup()
+		return "", nil, func() {}, err
+	}
+
+	archivePath := source
+	if source == "-" {
+		spooled, err := spoolToTempFile(stdin)
+		if err != nil {
+			return fail(fmt.Errorf("error reading archive from stdin: %v", err))
+		}
+		temporary = append(temporary, spooled)
+		archivePath = spooled
+		source = "stdin"
+	}
+
+	format, err := sniffArchiveFormat(archivePath)
+	if err != nil {
+		return fail(fmt.Errorf("error reading archive '%s': %v", source, err))
+	}
+
+	zipPath := archivePath
+	if format != archiveFormatZip {
+		converted, err := os.CreateTemp("", "gh-runtime-bundle-*.zip")
+		if err != nil {
+			return fail(fmt.Errorf("error creating zip file: %v", err))
+		}
+		converted.Close()
+		temporary = append(temporary, converted.Name())
+		zipPath = converted.Name()
+
+		err = convertTarToZip(archivePath, format == archiveFormatTarGz, zipPath)
+		if err != nil {
+			return fail(fmt.Errorf("error converting archive '%s' to zip: %v", source, err))
+		}
+	}
+
+	entries, err := validateZipBundle(zipPath)
+	if err != nil {
+		return fail(fmt.Errorf("invalid archive '%s': %v", source, err))
+	}
+
+	return zipPath, entries, cleanup, nil
+}
+
+func spoolToTempFile(r io.Reader) (string, error) {
+	f, err := os.CreateTemp("", "gh-runtime-archive-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// sniffArchiveFormat detects the format of an archive from its first bytes,
+// so that archives read from stdin or with unusual names are recognized.
+func sniffArchiveFormat(archivePath string) (string, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return archiveFormatZip, nil
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return archiveFormatTarGz, nil
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return archiveFormatTar, nil
+	}
+	return "", fmt.Errorf("unsupported archive format, expected a zip, tar or tar.gz archive")
+}
+
+// convertTarToZip writes the directories, regular files and symbolic links of
+// a tar archive to a zip bundle, stopping once more than the allowed content
+// has been read.
+func convertTarToZip(tarPath string, gzipped bool, zipPath string) error {
+	in, err := os.Open(tarPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	var r io.Reader = bufio.NewReader(in)
+	if gzipped {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	zipWriter := zip.NewWriter(out)
+
+	var total int64
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		zipHeader := &zip.FileHeader{Name: strings.TrimPrefix(header.Name, "./"), Modified: header.ModTime}
+		var content io.Reader
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if zipHeader.Name == "" || zipHeader.Name == "." {
+				continue
+			}
+			zipHeader.Name = strings.TrimSuffix(zipHeader.Name, "/") + "/"
+			zipHeader.SetMode(fs.ModeDir | 0755)
+		case tar.TypeReg:
+			zipHeader.Method = zip.Deflate
+			zipHeader.SetMode(normalizedMode(fs.FileMode(header.Mode).Perm()))
+			total += header.Size
+			if total > maxArchiveContentSize {
+				return errArchiveTooLarge
+			}
+			content = tr
+		case tar.TypeSymlink:
+			zipHeader.SetMode(fs.ModeSymlink | 0777)
+			content = strings.NewReader(header.Linkname)
+		case tar.TypeXGlobalHeader:
+			continue
+		default:
+			return fmt.Errorf("entry '%s' has an unsupported type, only directories, regular files and symbolic links can be deployed", header.Name)
+		}
+
+		w, err := zipWriter.CreateHeader(zipHeader)
+		if err != nil {
+			return err
+		}
+		if content != nil {
+			if _, err := io.Copy(w, content); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// validateZipBundle checks that a zip bundle is safe to deploy and returns
+// its entries.
+func validateZipBundle(zipPath string) ([]bundleEntry, error) {
+	inspection, err := inspectZipBundle(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(inspection.problems) > 0 {
+		return nil, errors.New(strings.Join(inspection.problems, "; "))
+	}
+	return inspection.entries, nil
+}
+
+// zipInspection is what inspectZipBundle found in a zip bundle.
+type zipInspection struct {
+	entries []bundleEntry
+	// files holds the size and digest of every file and symbolic link, by name.
+	files map[string]manifestFile
+	// problems describes every entry that makes the bundle unsafe to deploy.
+	problems []string
+}
+
+// inspectZipBundle checks every entry of a zip bundle for paths that escape
+// the bundle, duplicates, unsupported types and signs of a zip bomb. Every
+// entry is decompressed, so that sizes recorded in the archive cannot hide
+// how much data it really holds. An error is only returned if the bundle
+// cannot be read at all.
+func inspectZipBundle(zipPath string) (zipInspection, error) {
+	inspection := zipInspection{files: map[string]manifestFile{}}
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return inspection, err
+	}
+	defer reader.Close()
+
+	if len(reader.File) > maxArchiveEntries {
+		inspection.problems = append(inspection.problems, fmt.Sprintf("archive has %d entries, more than the maximum of %d", len(reader.File), maxArchiveEntries))
+		return inspection, nil
+	}
+
+	seen := map[string]bool{}
+	var total int64
+	for _, f := range reader.File {
+		problem := inspectZipEntry(f, seen, maxArchiveContentSize-total, &inspection)
+		if problem != "" {
+			inspection.problems = append(inspection.problems, problem)
+			if problem == errArchiveTooLarge.Error() {
+				break
+			}
+			continue
+		}
+		total += inspection.files[f.Name].Size
+	}
+	inspection.problems = append(inspection.problems, archiveLinkProblems(inspection.entries)...)
+
+	return inspection, nil
+}
+
+// inspectZipEntry checks a single entry of a zip bundle and records it in the
+// inspection. It returns a description of the problem with the entry, if any.
+func inspectZipEntry(f *zip.File, seen map[string]bool, remaining int64, inspection *zipInspection) string {
+	err := validateArchiveName(f.Name)
+	if err != nil {
+		return err.Error()
+	}
+	key := strings.TrimSuffix(f.Name, "/")
+	if seen[key] {
+		return fmt.Sprintf("entry '%s' appears more than once", f.Name)
+	}
+	seen[key] = true
+
+	mode := f.Mode()
+	isLink := mode&fs.ModeSymlink != 0
+	if !mode.IsDir() && !mode.IsRegular() && !isLink {
+		return fmt.Sprintf("entry '%s' has an unsupported type, only directories, regular files and symbolic links can be deployed", f.Name)
+	}
+	if f.UncompressedSize64 > archiveRatioMinSize && f.CompressedSize64 > 0 && f.UncompressedSize64/f.CompressedSize64 > maxArchiveCompressionRatio {
+		return fmt.Sprintf("entry '%s' has a suspicious compression ratio of more than %d:1", f.Name, maxArchiveCompressionRatio)
+	}
+
+	entry := bundleEntry{name: f.Name, path: f.Name, info: f.FileInfo()}
+	if mode.IsDir() {
+		inspection.entries = append(inspection.entries, entry)
+		return ""
+	}
+
+	file, content, err := readArchiveEntry(f, remaining, isLink)
+	if err != nil {
+		return err.Error()
+	}
+	if isLink {
+		entry.linkTarget = string(content)
+		file.Link = entry.linkTarget
+		err = validateArchiveLink(f.Name, entry.linkTarget)
+		if err != nil {
+			return err.Error()
+		}
+	}
+
+	inspection.entries = append(inspection.entries, entry)
+	inspection.files[f.Name] = file
+	return ""
+}
+
+// readArchiveEntry decompresses an entry, reading at most limit bytes, and
+// returns its size and digest. The content is only returned for symbolic
+// links.
+func readArchiveEntry(f *zip.File, limit int64, isLink bool) (manifestFile, []byte, error) {
+	file := manifestFile{Path: f.Name}
+	rc, err := f.Open()
+	if err != nil {
+		return file, nil, fmt.Errorf("error reading entry '%s': %v", f.Name, err)
+	}
+	defer rc.Close()
+
+	var content bytes.Buffer
+	digest := sha256.New()
+	var w io.Writer = digest
+	if isLink {
+		w = io.MultiWriter(digest, &content)
+		if limit > maxArchiveLinkTarget {
+			limit = maxArchiveLinkTarget
+		}
+	}
+
+	n, err := io.Copy(w, io.LimitReader(rc, limit+1))
+	if err != nil {
+		return file, nil, fmt.Errorf("error reading entry '%s': %v", f.Name, err)
+	}
+	if n > limit {
+		if isLink {
+			return file, nil, fmt.Errorf("symbolic link '%s' has a target longer than %d bytes", f.Name, maxArchiveLinkTarget)
+		}
+		return file, nil, errArchiveTooLarge
+	}
+
+	file.Size = n
+	file.SHA256 = hex.EncodeToString(digest.Sum(nil))
+	return file, content.Bytes(), nil
+}
+
+// validateArchiveName rejects entry names that could be written outside of
+// the directory the bundle is extracted to.
+func validateArchiveName(name string) error {
+	if name == "" {
+		return fmt.Errorf("archive has an entry with an empty name")
+	}
+	if strings.Contains(name, "\\") {
+		return fmt.Errorf("entry '%s' uses backslashes as path separators", name)
+	}
+	if path.IsAbs(name) || (len(name) >= 2 && name[1] == ':') {
+		return fmt.Errorf("entry '%s' has an absolute path", name)
+	}
+	for _, part := range strings.Split(strings.TrimSuffix(name, "/"), "/") {
+		if part == ".." {
+			return fmt.Errorf("entry '%s' refers to a parent directory", name)
+		}
+	}
+	return nil
+}
+
+// archiveLinkProblems describes the entries that are stored below a symbolic
+// link, and the symbolic links whose target goes through another one. Such
+// chains can point outside of the bundle even though every link looks safe on
+// its own, like "d/l -> .." followed by "d/l2 -> l/..".
+func archiveLinkProblems(entries []bundleEntry) []string {
+	links := map[string]bool{}
+	for _, entry := range entries {
+		if entry.linkTarget != "" {
+			links[entry.name] = true
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+
+	problems := []string{}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.name, "/")
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if links[dir] {
+				problems = append(problems, fmt.Sprintf("entry '%s' is stored below symbolic link '%s'", entry.name, dir))
+				break
+			}
+		}
+		if entry.linkTarget == "" {
+			continue
+		}
+
+		// Resolve the target one element at a time, so that every directory
+		// it passes through can be checked.
+		resolved := path.Dir(name)
+		elements := strings.Split(entry.linkTarget, "/")
+		for i, element := range elements {
+			resolved = path.Join(resolved, element)
+			if i < len(elements)-1 && links[resolved] {
+				problems = append(problems, fmt.Sprintf("symbolic link '%s' points through symbolic link '%s'", entry.name, resolved))
+				break
+			}
+		}
+	}
+	return problems
+}
+
+// validateArchiveLink rejects symbolic links that point outside of the bundle.
+func validateArchiveLink(name, target string) error {
+	if target == "" || path.IsAbs(target) || strings.Contains(target, "\\") {
+		return fmt.Errorf("symbolic link '%s' must have a relative target", name)
+	}
+	resolved := path.Join(path.Dir(name), target)
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return fmt.Errorf("symbolic link '%s' points outside of the archive", name)
+	}
+	return nil
+}
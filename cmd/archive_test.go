@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testArchiveEntry is an entry of an archive built by a test. Names ending
+// with "/" are directories.
+type testArchiveEntry struct {
+	name       string
+	content    string
+	linkTarget string
+}
+
+func writeTestZip(t *testing.T, zipPath string, entries []testArchiveEntry) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		content := e.content
+		switch {
+		case strings.HasSuffix(e.name, "/"):
+			header.SetMode(fs.ModeDir | 0755)
+		case e.linkTarget != "":
+			header.SetMode(fs.ModeSymlink | 0777)
+			content = e.linkTarget
+		default:
+			header.SetMode(0644)
+		}
+		f, err := w.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = io.WriteString(f, content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, os.WriteFile(zipPath, buf.Bytes(), 0644))
+}
+
+func writeTestTarGz(t *testing.T, tarPath string, entries []testArchiveEntry) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		switch {
+		case strings.HasSuffix(e.name, "/"):
+			header.Typeflag, header.Mode, header.Size = tar.TypeDir, 0755, 0
+		case e.linkTarget != "":
+			header.Typeflag, header.Linkname, header.Size = tar.TypeSymlink, e.linkTarget, 0
+		}
+		require.NoError(t, w.WriteHeader(header))
+		_, err := io.WriteString(w, e.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, os.WriteFile(tarPath, buf.Bytes(), 0644))
+}
+
+func TestRunDeploy_ZipArchiveIsUploadedAsIs(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "dist.zip")
+	writeTestZip(t, archivePath, []testArchiveEntry{
+		{name: "assets/"},
+		{name: "assets/app.js", content: "console.log(1)"},
+		{name: "index.html", content: "<html></html>"},
+	})
+	original, err := os.ReadFile(archivePath)
+	require.NoError(t, err)
+
+	var uploaded []byte
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			uploaded, err = io.ReadAll(body)
+			return err
+		},
+	}
+
+	err = runDeploy(client, deployCmdFlags{archive: archivePath, app: "my-app"})
+	require.NoError(t, err)
+	assert.Equal(t, original, uploaded)
+}
+
+func TestRunDeploy_TarGzArchiveIsConverted(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "dist.tar.gz")
+	writeTestTarGz(t, archivePath, []testArchiveEntry{
+		{name: "./"},
+		{name: "./index.html", content: "<html></html>"},
+		{name: "./docs/"},
+		{name: "./docs/index.html", content: "docs"},
+		{name: "./latest", linkTarget: "docs"},
+	})
+
+	var postPath string
+	var files map[string]string
+	err := runDeploy(capturingPostClient(t, &postPath, &files), deployCmdFlags{archive: archivePath, app: "my-app"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"index.html": "<html></html>", "docs/index.html": "docs", "latest": "docs"}, files)
+}
+
+func TestPrepareArchive_Stdin(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "dist.zip")
+	writeTestZip(t, archivePath, []testArchiveEntry{{name: "index.html", content: "stdin"}})
+	data, err := os.ReadFile(archivePath)
+	require.NoError(t, err)
+
+	zipPath, entries, cleanup, err := prepareArchive("-", bytes.NewReader(data))
+	require.NoError(t, err)
+	defer cleanup()
+
+	spooled, err := os.ReadFile(zipPath)
+	require.NoError(t, err)
+	assert.Equal(t, data, spooled)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "index.html", entries[0].name)
+
+	cleanup()
+	_, err = os.Stat(zipPath)
+	assert.True(t, os.IsNotExist(err), "the spooled archive should be removed")
+}
+
+func TestRunDeploy_ArchiveWithDir(t *testing.T) {
+	err := runDeploy(&mockRESTClient{}, deployCmdFlags{archive: "dist.zip", dir: "dist", app: "my-app"})
+	require.ErrorContains(t, err, "--dir cannot be used with --archive")
+}
+
+func TestPrepareArchive_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []testArchiveEntry
+		wantErr string
+	}{
+		{"parent directory", []testArchiveEntry{{name: "../evil.html", content: "x"}}, "refers to a parent directory"},
+		{"nested parent directory", []testArchiveEntry{{name: "a/../../evil.html", content: "x"}}, "refers to a parent directory"},
+		{"absolute path", []testArchiveEntry{{name: "/etc/passwd", content: "x"}}, "has an absolute path"},
+		{"drive letter", []testArchiveEntry{{name: "C:/evil.html", content: "x"}}, "has an absolute path"},
+		{"backslashes", []testArchiveEntry{{name: "a\\b.html", content: "x"}}, "uses backslashes"},
+		{"duplicate", []testArchiveEntry{{name: "index.html", content: "a"}, {name: "index.html", content: "b"}}, "appears more than once"},
+		{"link outside", []testArchiveEntry{{name: "docs/link", linkTarget: "../../secret"}}, "points outside of the archive"},
+		{"absolute link", []testArchiveEntry{{name: "link", linkTarget: "/etc/passwd"}}, "must have a relative target"},
+		{"chained links", []testArchiveEntry{{name: "d/l", linkTarget: ".."}, {name: "d/l2", linkTarget: "l/.."}}, "symbolic link 'd/l2' points through symbolic link 'd/l'"},
+		{"chained links in any order", []testArchiveEntry{{name: "d/l2", linkTarget: "l/../.."}, {name: "d/l", linkTarget: "."}}, "symbolic link 'd/l2' points through symbolic link 'd/l'"},
+		{"entry below link", []testArchiveEntry{{name: "d/l", linkTarget: ".."}, {name: "d/l/evil.html", content: "x"}}, "entry 'd/l/evil.html' is stored below symbolic link 'd/l'"},
+		{"compression ratio", []testArchiveEntry{{name: "zeros.bin", content: strings.Repeat("\x00", 4<<20)}}, "suspicious compression ratio"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := filepath.Join(t.TempDir(), "dist.zip")
+			writeTestZip(t, archivePath, tt.entries)
+
+			_, _, _, err := prepareArchive(archivePath, nil)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPrepareArchive_InvalidTarGz(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "dist.tar.gz")
+	writeTestTarGz(t, archivePath, []testArchiveEntry{{name: "../evil.html", content: "x"}})
+
+	_, _, _, err := prepareArchive(archivePath, nil)
+	require.ErrorContains(t, err, "refers to a parent directory")
+}
+
+func TestPrepareArchive_UnsupportedFormat(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "dist.rar")
+	require.NoError(t, os.WriteFile(archivePath, []byte("not an archive"), 0644))
+
+	_, _, _, err := prepareArchive(archivePath, nil)
+	require.ErrorContains(t, err, "unsupported archive format")
+}
+
+func TestPrepareArchive_Missing(t *testing.T) {
+	_, _, _, err := prepareArchive(filepath.Join(t.TempDir(), "missing.zip"), nil)
+	require.ErrorContains(t, err, "error reading archive")
+}
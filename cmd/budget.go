@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// sizeReportTopN is the number of largest files and directories listed in a
+// bundle size report.
+const sizeReportTopN = 10
+
+// bundleBudget holds the size limits a bundle must fit in. A zero limit is not
+// enforced.
+type bundleBudget struct {
+	// maxSize is the largest allowed size of the zip bundle, in bytes.
+	maxSize int64
+	// maxFileSize is the largest allowed size of a single file, in bytes.
+	maxFileSize int64
+}
+
+// sizedPath is a file or directory in a bundle along with its size.
+type sizedPath struct {
+	name string
+	size int64
+}
+
+// sizeReport summarizes the size of a bundle.
+type sizeReport struct {
+	// bundleSize is the size of the zip bundle.
+	bundleSize int64
+	// contentSize is the uncompressed size of all files in the bundle.
+	contentSize int64
+	fileCount   int
+	// files and dirs hold every file and directory, largest first.
+	files []sizedPath
+	dirs  []sizedPath
+}
+
+// byteSizeUnits maps the suffixes accepted by parseByteSize to their multiplier.
+var byteSizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1e3,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1e6,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1e9,
+	"gib": 1 << 30,
+}
+
+// parseByteSize parses a size such as "512", "250KB", "1.5 MiB" or "2G".
+// KB, MB and GB are decimal units, while KiB, MiB, GiB and the single letter
+// suffixes are binary units. An empty string parses as zero.
+func parseByteSize(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	split := strings.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	number, unit := value, ""
+	if split >= 0 {
+		number, unit = value[:split], strings.TrimSpace(value[split:])
+	}
+
+	multiplier, ok := byteSizeUnits[strings.ToLower(unit)]
+	if !ok {
+		return 0, fmt.Errorf("invalid size '%s': unknown unit '%s'", value, unit)
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size '%s'", value)
+	}
+
+	return int64(n * multiplier), nil
+}
+
+// formatByteSize formats a size in bytes using binary units.
+func formatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// resolveBundleBudget returns the size limits for a deploy. Flags take
+// precedence over the runtime configuration file.
+func resolveBundleBudget(maxSizeFlag, maxFileSizeFlag, maxSizeConfig, maxFileSizeConfig string) (bundleBudget, error) {
+	maxSizeValue := maxSizeFlag
+	if maxSizeValue == "" {
+		maxSizeValue = maxSizeConfig
+	}
+	maxFileSizeValue := maxFileSizeFlag
+	if maxFileSizeValue == "" {
+		maxFileSizeValue = maxFileSizeConfig
+	}
+
+	maxSize, err := parseByteSize(maxSizeValue)
+	if err != nil {
+		return bundleBudget{}, fmt.Errorf("invalid maximum bundle size: %v", err)
+	}
+	maxFileSize, err := parseByteSize(maxFileSizeValue)
+	if err != nil {
+		return bundleBudget{}, fmt.Errorf("invalid maximum file size: %v", err)
+	}
+
+	return bundleBudget{maxSize: maxSize, maxFileSize: maxFileSize}, nil
+}
+
+// newSizeReport builds a size report for the given bundle entries. Directory
+// sizes include everything below them.
+func newSizeReport(entries []bundleEntry, bundleSize int64) sizeReport {
+	report := sizeReport{bundleSize: bundleSize}
+	dirSizes := map[string]int64{}
+
+	for _, entry := range entries {
+		if !entry.info.Mode().IsRegular() || entry.linkTarget != "" {
+			continue
+		}
+
+		size := entry.info.Size()
+		report.contentSize += size
+		report.fileCount++
+		report.files = append(report.files, sizedPath{name: entry.name, size: size})
+
+		for dir := path.Dir(entry.name); dir != "."; dir = path.Dir(dir) {
+			dirSizes[dir+"/"] += size
+		}
+	}
+
+	for name, size := range dirSizes {
+		report.dirs = append(report.dirs, sizedPath{name: name, size: size})
+	}
+	sortBySize(report.files)
+	sortBySize(report.dirs)
+
+	return report
+}
+
+func sortBySize(paths []sizedPath) {
+	sort.Slice(paths, func(i, j int) bool {
+		if paths[i].size != paths[j].size {
+			return paths[i].size > paths[j].size
+		}
+		return paths[i].name < paths[j].name
+	})
+}
+
+// write prints the report, listing at most topN files and directories.
+func (r sizeReport) write(w io.Writer, topN int) {
+	fmt.Fprintf(w, "Bundle size: %s (%s uncompressed, %d files)\n", formatByteSize(r.bundleSize), formatByteSize(r.contentSize), r.fileCount)
+
+	writeSizedPaths(w, "Largest files:", r.files, topN)
+	writeSizedPaths(w, "Largest directories:", r.dirs, topN)
+}
+
+func writeSizedPaths(w io.Writer, title string, paths []sizedPath, topN int) {
+	if len(paths) == 0 {
+		return
+	}
+	if len(paths) > topN {
+		paths = paths[:topN]
+	}
+
+	fmt.Fprintln(w, title)
+	for _, p := range paths {
+		fmt.Fprintf(w, "  %10s  %s\n", formatByteSize(p.size), p.name)
+	}
+}
+
+// check returns an error describing every limit of the budget that the report
+// exceeds.
+func (b bundleBudget) check(r sizeReport) error {
+	var problems []string
+
+	if b.maxSize > 0 && r.bundleSize > b.maxSize {
+		problems = append(problems, fmt.Sprintf("bundle size %s exceeds the maximum of %s", formatByteSize(r.bundleSize), formatByteSize(b.maxSize)))
+	}
+
+	if b.maxFileSize > 0 {
+		for _, f := range r.files {
+			if f.size <= b.maxFileSize {
+				break
+			}
+			problems = append(problems, fmt.Sprintf("file '%s' (%s) exceeds the maximum file size of %s", f.name, formatByteSize(f.size), formatByteSize(b.maxFileSize)))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("bundle exceeds its size budget:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{"", 0},
+		{"512", 512},
+		{"512B", 512},
+		{"250KB", 250000},
+		{"250kb", 250000},
+		{"1.5 MiB", 1572864},
+		{"2G", 2 << 30},
+		{"1GB", 1000000000},
+	}
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.value)
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, got, tt.value)
+	}
+
+	for _, value := range []string{"ten", "10XB", "-5MB", "1..5MB"} {
+		_, err := parseByteSize(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestFormatByteSize(t *testing.T) {
+	assert.Equal(t, "512 B", formatByteSize(512))
+	assert.Equal(t, "1.0 KiB", formatByteSize(1024))
+	assert.Equal(t, "1.5 MiB", formatByteSize(1572864))
+	assert.Equal(t, "2.0 GiB", formatByteSize(2<<30))
+}
+
+func TestResolveBundleBudget_FlagsOverrideConfig(t *testing.T) {
+	budget, err := resolveBundleBudget("10MB", "", "20MB", "1MB")
+	require.NoError(t, err)
+	assert.Equal(t, bundleBudget{maxSize: 10000000, maxFileSize: 1000000}, budget)
+
+	_, err = resolveBundleBudget("", "lots", "", "")
+	require.ErrorContains(t, err, "invalid maximum file size")
+}
+
+func TestSizeReport(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{
+		"index.html":           "12345",
+		"assets/app.js":        "1234567890",
+		"assets/img/logo.svg":  "1234567",
+		"assets/img/small.svg": "1",
+	})
+
+	entries, err := collectBundleEntries(src, bundleOptions{})
+	require.NoError(t, err)
+
+	report := newSizeReport(entries, 100)
+	assert.Equal(t, int64(23), report.contentSize)
+	assert.Equal(t, 4, report.fileCount)
+	assert.Equal(t, []sizedPath{
+		{"assets/app.js", 10},
+		{"assets/img/logo.svg", 7},
+		{"index.html", 5},
+		{"assets/img/small.svg", 1},
+	}, report.files)
+	assert.Equal(t, []sizedPath{
+		{"assets/", 18},
+		{"assets/img/", 8},
+	}, report.dirs)
+
+	var out bytes.Buffer
+	report.write(&out, 2)
+	assert.Contains(t, out.String(), "Bundle size: 100 B (23 B uncompressed, 4 files)")
+	assert.Contains(t, out.String(), "assets/app.js")
+	assert.Contains(t, out.String(), "assets/img/logo.svg")
+	assert.NotContains(t, out.String(), "index.html")
+}
+
+func TestBundleBudgetCheck(t *testing.T) {
+	report := sizeReport{
+		bundleSize: 2048,
+		files:      []sizedPath{{"big.js", 1500}, {"medium.js", 900}, {"small.js", 10}},
+	}
+
+	assert.NoError(t, bundleBudget{}.check(report))
+	assert.NoError(t, bundleBudget{maxSize: 4096, maxFileSize: 2000}.check(report))
+
+	err := bundleBudget{maxSize: 1024}.check(report)
+	require.ErrorContains(t, err, "bundle size 2.0 KiB exceeds the maximum of 1.0 KiB")
+
+	err = bundleBudget{maxFileSize: 800}.check(report)
+	require.ErrorContains(t, err, "file 'big.js'")
+	require.ErrorContains(t, err, "file 'medium.js'")
+	assert.NotContains(t, err.Error(), "small.js")
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Environment variables exported to the build command.
+const (
+	buildEnvAppID        = "GH_RUNTIME_APP_ID"
+	buildEnvRevisionName = "GH_RUNTIME_REVISION_NAME"
+	buildEnvRevision     = "GH_RUNTIME_REVISION"
+)
+
+// buildEnv returns the environment variables describing the deploy that are
+// passed to the build command.
+func buildEnv(appName, revisionName, sha string) []string {
+	return []string{
+		buildEnvAppID + "=" + appName,
+		buildEnvRevisionName + "=" + revisionName,
+		buildEnvRevision + "=" + sha,
+	}
+}
+
+// runBuildCommand runs command through the system shell in dir, or in the
+// current directory if dir is empty, streaming its output to stdout and
+// stderr. The variables in env are added to the current environment.
+func runBuildCommand(command, dir string, env []string, stdout, stderr io.Writer) error {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", command)
+	} else {
+		c = exec.Command("sh", "-c", command)
+	}
+	c.Dir = dir
+	c.Env = append(os.Environ(), env...)
+	c.Stdin = os.Stdin
+	c.Stdout = stdout
+	c.Stderr = stderr
+
+	fmt.Fprintf(stdout, "Running build command: %s\n", command)
+	err := c.Run()
+	if err != nil {
+		return fmt.Errorf("build command '%s' failed: %w", command, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("build command tests use POSIX shell syntax")
+	}
+}
+
+func TestRunBuildCommand_StreamsOutputAndEnv(t *testing.T) {
+	skipWithoutShell(t)
+
+	var stdout, stderr bytes.Buffer
+	err := runBuildCommand(`echo "$GH_RUNTIME_APP_ID $GH_RUNTIME_REVISION_NAME $GH_RUNTIME_REVISION"; echo oops >&2`, "",
+		buildEnv("my-app", "v2", "abc123"), &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "my-app v2 abc123")
+	assert.Equal(t, "oops\n", stderr.String())
+}
+
+func TestRunBuildCommand_Failure(t *testing.T) {
+	skipWithoutShell(t)
+
+	var stdout, stderr bytes.Buffer
+	err := runBuildCommand("exit 3", "", nil, &stdout, &stderr)
+	require.ErrorContains(t, err, "build command 'exit 3' failed")
+}
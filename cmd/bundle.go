@@ -0,0 +1,471 @@
+package cmd
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/github/gh-runtime-cli/internal/routes"
+)
+
+// defaultBundleModTime is the timestamp applied to every entry of a reproducible
+// bundle when SOURCE_DATE_EPOCH is not set. It is the earliest date a zip file
+// can represent, so the archive does not depend on when it was built.
+var defaultBundleModTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// bundleOptions controls how a directory is packaged into a zip bundle.
+type bundleOptions struct {
+	// reproducible sorts entries and normalizes timestamps and permissions so
+	// that identical content always produces a byte-identical archive.
+	reproducible bool
+	// modTime is the timestamp applied to every entry when reproducible is set.
+	modTime time.Time
+	// symlinks is the policy for symbolic links: symlinksFollow,
+	// symlinksPreserve or symlinksError.
+	symlinks string
+	// warnings receives messages about entries that were skipped.
+	warnings io.Writer
+	// manifest adds a manifest of every file to the bundle, see
+	// bundleManifestPath.
+	manifest bool
+	// routes are added to the bundle at bundleRoutesPath if set.
+	routes *routes.Config
+	// compression chooses how each entry is compressed in the zip.
+	compression bundleCompression
+	// precompress adds gzip and brotli variants of compressible files of at
+	// least precompressMinSize bytes, see precompressEntry.
+	precompress        bool
+	precompressMinSize int64
+	// skipProjectFiles leaves bundleIgnoredNames out of the bundle. It is set
+	// when the directory was inferred from the detected framework, since that
+	// can be the project itself.
+	skipProjectFiles bool
+}
+
+// Policies for symbolic links found while bundling. Links that resolve outside
+// of the deploy directory are always rejected.
+const (
+	// symlinksFollow stores the content of the link target.
+	symlinksFollow = "follow"
+	// symlinksPreserve stores the link itself.
+	symlinksPreserve = "preserve"
+	// symlinksError fails the bundle.
+	symlinksError = "error"
+)
+
+// bundleIgnoredNames are version control, dependency and OS metadata files and
+// directories that are left out of bundles with skipProjectFiles, wherever
+// they appear. Directories given with --dir or the runtime config file are
+// bundled as they are.
+var bundleIgnoredNames = map[string]bool{
+	".git":         true,
+	".hg":          true,
+	".svn":         true,
+	"node_modules": true,
+	".DS_Store":    true,
+	"Thumbs.db":    true,
+}
+
+// bundleEntry is a single file or directory to be written to a bundle.
+type bundleEntry struct {
+	// name is the slash-separated path of the entry inside the bundle.
+	name string
+	// path is the location of the entry on disk.
+	path string
+	info os.FileInfo
+	// linkTarget is set for symbolic links preserved as links.
+	linkTarget string
+}
+
+// newBundleOptions returns the bundle options for a deploy. Reproducible bundles
+// use SOURCE_DATE_EPOCH as the entry timestamp when it is set.
+func newBundleOptions(reproducible bool, symlinks string) (bundleOptions, error) {
+	opts := bundleOptions{reproducible: reproducible, symlinks: symlinks, warnings: os.Stderr, manifest: true}
+	switch symlinks {
+	case "":
+		opts.symlinks = symlinksFollow
+	case symlinksFollow, symlinksPreserve, symlinksError:
+	default:
+		return opts, fmt.Errorf("invalid --symlinks value '%s': must be one of 'follow', 'preserve' or 'error'", symlinks)
+	}
+
+	if !reproducible {
+		return opts, nil
+	}
+
+	modTime, err := sourceDateEpoch()
+	if err != nil {
+		return opts, err
+	}
+	opts.modTime = modTime
+	return opts, nil
+}
+
+// sourceDateEpoch returns the time in SOURCE_DATE_EPOCH, or defaultBundleModTime
+// if the variable is unset.
+// See https://reproducible-builds.org/specs/source-date-epoch/
+func sourceDateEpoch() (time.Time, error) {
+	value := os.Getenv("SOURCE_DATE_EPOCH")
+	if value == "" {
+		return defaultBundleModTime, nil
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH '%s': must be a number of seconds since the Unix epoch", value)
+	}
+
+	modTime := time.Unix(seconds, 0).UTC()
+	// Zip timestamps cannot represent anything earlier than 1980.
+	if modTime.Before(defaultBundleModTime) {
+		modTime = defaultBundleModTime
+	}
+	return modTime, nil
+}
+
+// zipDirectory writes the contents of sourceDir to destinationZip and returns
+// the entries that were written.
+func zipDirectory(sourceDir, destinationZip string, opts bundleOptions) ([]bundleEntry, error) {
+	entries, err := collectBundleEntries(sourceDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error zipping directory '%s': %w", sourceDir, err)
+	}
+
+	reserved := map[string]string{}
+	if opts.manifest {
+		reserved[bundleManifestPath] = "the bundle manifest"
+	}
+	if opts.routes != nil {
+		reserved[bundleRoutesPath] = "the routes of the app"
+	}
+	for _, entry := range entries {
+		if purpose, ok := reserved[entry.name]; ok {
+			return nil, fmt.Errorf("error zipping directory '%s': '%s' is reserved for %s", sourceDir, entry.name, purpose)
+		}
+	}
+
+	if opts.reproducible {
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].name < entries[j].name
+		})
+	}
+
+	zipFile, err := os.Create(destinationZip)
+	if err != nil {
+		return nil, fmt.Errorf("error creating zip file '%s': %w", destinationZip, err)
+	}
+	defer zipFile.Close()
+
+	zipWriter := zip.NewWriter(zipFile)
+	defer zipWriter.Close()
+	opts.compression.register(zipWriter)
+
+	taken := map[string]bool{}
+	for _, entry := range entries {
+		taken[entry.name] = true
+	}
+
+	manifest := bundleManifest{Version: bundleManifestVersion, Files: []manifestFile{}}
+	for _, entry := range entries {
+		file, err := writeBundleEntry(zipWriter, entry, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error zipping directory '%s': %w", sourceDir, err)
+		}
+		if entry.info.IsDir() {
+			continue
+		}
+
+		var variants []manifestFile
+		if opts.precompress && entry.linkTarget == "" && precompressible(entry.name, file.Size, opts.precompressMinSize) {
+			variants, file.Encodings, err = precompressEntry(zipWriter, entry, taken, opts)
+			if err != nil {
+				return nil, fmt.Errorf("error zipping directory '%s': %w", sourceDir, err)
+			}
+		}
+		manifest.Files = append(manifest.Files, file)
+		manifest.Files = append(manifest.Files, variants...)
+	}
+
+	if opts.routes != nil {
+		file, err := writeBundleRoutes(zipWriter, *opts.routes, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error zipping directory '%s': %w", sourceDir, err)
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	if opts.manifest {
+		err = writeBundleManifest(zipWriter, manifest, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error zipping directory '%s': %w", sourceDir, err)
+		}
+	}
+
+	err = zipWriter.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error writing zip file '%s': %w", destinationZip, err)
+	}
+
+	return entries, nil
+}
+
+// bundleCollector gathers the entries of a bundle, applying the symlink policy
+// and keeping every entry inside the deploy root.
+type bundleCollector struct {
+	root    string
+	opts    bundleOptions
+	entries []bundleEntry
+	// visiting holds the real paths of the directories currently being walked,
+	// to detect symlink cycles.
+	visiting map[string]bool
+}
+
+// collectBundleEntries walks sourceDir and returns every file and directory
+// below it, except for bundleIgnoredNames if opts.skipProjectFiles is set.
+// Symbolic links are handled according to opts.symlinks, and anything that is
+// not a regular file, directory or symlink is skipped with a warning.
+func collectBundleEntries(sourceDir string, opts bundleOptions) ([]bundleEntry, error) {
+	root, err := filepath.EvalSymlinks(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving directory '%s': %w", sourceDir, err)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving directory '%s': %w", sourceDir, err)
+	}
+
+	c := &bundleCollector{root: root, opts: opts, visiting: map[string]bool{}}
+	err = c.walkDir(sourceDir, "")
+	if err != nil {
+		return nil, err
+	}
+	return c.entries, nil
+}
+
+func (c *bundleCollector) warnf(format string, args ...interface{}) {
+	c.opts.warnf(format, args...)
+}
+
+// warnf reports a problem that does not fail the bundle.
+func (opts bundleOptions) warnf(format string, args ...interface{}) {
+	if opts.warnings != nil {
+		fmt.Fprintf(opts.warnings, "warning: "+format+"\n", args...)
+	}
+}
+
+func (c *bundleCollector) walkDir(dir, prefix string) error {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err == nil {
+		realDir, err = filepath.Abs(realDir)
+	}
+	if err != nil {
+		return fmt.Errorf("error accessing path '%s': %w", dir, err)
+	}
+	c.visiting[realDir] = true
+	defer delete(c.visiting, realDir)
+
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error accessing path '%s': %w", dir, err)
+	}
+
+	for _, item := range items {
+		if c.opts.skipProjectFiles && bundleIgnoredNames[item.Name()] {
+			continue
+		}
+
+		path := filepath.Join(dir, item.Name())
+		info, err := os.Lstat(path)
+		if err != nil {
+			return fmt.Errorf("error accessing path '%s': %w", path, err)
+		}
+
+		err = c.add(path, prefix+item.Name(), info)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *bundleCollector) add(path, name string, info os.FileInfo) error {
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		return c.addSymlink(path, name, info)
+	case info.IsDir():
+		c.entries = append(c.entries, bundleEntry{name: name + "/", path: path, info: info})
+		return c.walkDir(path, name+"/")
+	case info.Mode().IsRegular():
+		c.entries = append(c.entries, bundleEntry{name: name, path: path, info: info})
+		return nil
+	default:
+		c.warnf("skipping '%s': not a regular file (%s)", path, info.Mode().Type())
+		return nil
+	}
+}
+
+func (c *bundleCollector) addSymlink(path, name string, info os.FileInfo) error {
+	if c.opts.symlinks == symlinksError {
+		return fmt.Errorf("'%s' is a symbolic link; use --symlinks=follow or --symlinks=preserve to include it", path)
+	}
+
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("error resolving symbolic link '%s': %w", path, err)
+	}
+	target, err = filepath.Abs(target)
+	if err != nil {
+		return fmt.Errorf("error resolving symbolic link '%s': %w", path, err)
+	}
+	if !isWithinDir(c.root, target) {
+		return fmt.Errorf("symbolic link '%s' points to '%s', which is outside of the deploy directory", path, target)
+	}
+
+	if c.opts.symlinks == symlinksPreserve {
+		link, err := os.Readlink(path)
+		if err != nil {
+			return fmt.Errorf("error reading symbolic link '%s': %w", path, err)
+		}
+		if filepath.IsAbs(link) {
+			return fmt.Errorf("symbolic link '%s' has an absolute target '%s'; only relative links can be preserved", path, link)
+		}
+		c.entries = append(c.entries, bundleEntry{name: name, path: path, info: info, linkTarget: filepath.ToSlash(link)})
+		return nil
+	}
+
+	targetInfo, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("error accessing path '%s': %w", target, err)
+	}
+
+	switch {
+	case targetInfo.IsDir():
+		if c.visiting[target] {
+			c.warnf("skipping '%s': symbolic link cycle", path)
+			return nil
+		}
+		c.entries = append(c.entries, bundleEntry{name: name + "/", path: target, info: targetInfo})
+		return c.walkDir(path, name+"/")
+	case targetInfo.Mode().IsRegular():
+		c.entries = append(c.entries, bundleEntry{name: name, path: target, info: targetInfo})
+		return nil
+	default:
+		c.warnf("skipping '%s': not a regular file (%s)", path, targetInfo.Mode().Type())
+		return nil
+	}
+}
+
+// isWithinDir reports whether path is dir or one of its descendants. Both paths
+// must be absolute and free of symbolic links.
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// writeBundleEntry writes an entry to the bundle and returns how it is
+// recorded in the bundle manifest.
+func writeBundleEntry(zipWriter *zip.Writer, entry bundleEntry, opts bundleOptions) (manifestFile, error) {
+	file := manifestFile{Path: entry.name}
+	header, err := zip.FileInfoHeader(entry.info)
+	if err != nil {
+		return file, fmt.Errorf("error creating zip header for '%s': %w", entry.path, err)
+	}
+	header.Name = entry.name
+	if entry.info.Mode().IsRegular() {
+		header.Method = opts.compression.method(entry.name)
+	}
+
+	if opts.reproducible {
+		header.Modified = opts.modTime
+		header.SetMode(normalizedMode(entry.info.Mode()))
+	}
+
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return file, fmt.Errorf("error creating zip writer for '%s': %w", entry.path, err)
+	}
+
+	if entry.info.IsDir() {
+		return file, nil
+	}
+
+	digest := sha256.New()
+	writer = io.MultiWriter(writer, digest)
+
+	// Symbolic links are stored with the link target as their content.
+	if entry.linkTarget != "" {
+		n, err := io.WriteString(writer, entry.linkTarget)
+		if err != nil {
+			return file, fmt.Errorf("error writing symbolic link '%s' to zip: %w", entry.path, err)
+		}
+		file.Size = int64(n)
+		file.SHA256 = hex.EncodeToString(digest.Sum(nil))
+		file.Link = entry.linkTarget
+		return file, nil
+	}
+
+	f, err := os.Open(entry.path)
+	if err != nil {
+		return file, fmt.Errorf("error opening file '%s': %w", entry.path, err)
+	}
+	defer f.Close()
+
+	file.Size, err = io.Copy(writer, f)
+	if err != nil {
+		return file, fmt.Errorf("error writing file '%s' to zip: %w", entry.path, err)
+	}
+	file.SHA256 = hex.EncodeToString(digest.Sum(nil))
+
+	return file, nil
+}
+
+// writeGeneratedFile adds a file that is generated while bundling, rather
+// than read from the deploy directory, to the bundle.
+func writeGeneratedFile(zipWriter *zip.Writer, name string, data []byte, opts bundleOptions) (manifestFile, error) {
+	header := &zip.FileHeader{Name: name, Method: opts.compression.method(name), Modified: time.Now()}
+	header.SetMode(0644)
+	if opts.reproducible {
+		header.Modified = opts.modTime
+	}
+
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return manifestFile{}, fmt.Errorf("error creating zip writer for '%s': %w", name, err)
+	}
+	_, err = writer.Write(data)
+	if err != nil {
+		return manifestFile{}, fmt.Errorf("error writing '%s' to zip: %w", name, err)
+	}
+
+	digest := sha256.Sum256(data)
+	return manifestFile{Path: name, Size: int64(len(data)), SHA256: hex.EncodeToString(digest[:])}, nil
+}
+
+// normalizedMode maps a file mode to the permissions stored in a reproducible
+// bundle: 0755 for directories and executables, 0777 for symbolic links and
+// 0644 for everything else.
+func normalizedMode(mode fs.FileMode) fs.FileMode {
+	if mode.IsDir() {
+		return fs.ModeDir | 0755
+	}
+	if mode&fs.ModeSymlink != 0 {
+		return fs.ModeSymlink | 0777
+	}
+	if mode&0111 != 0 {
+		return 0755
+	}
+	return 0644
+}
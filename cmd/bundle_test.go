@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func hashFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestZipDirectory_ReproducibleHashIsStable(t *testing.T) {
+	tmp := t.TempDir()
+	files := map[string]string{
+		"index.html":       "<html></html>",
+		"assets/app.js":    "console.log('hi')",
+		"assets/app.css":   "body {}",
+		"nested/a/b/c.txt": "deep",
+	}
+
+	first := filepath.Join(tmp, "first")
+	writeTestTree(t, first, files)
+	firstZip := filepath.Join(tmp, "first.zip")
+	_, err := zipDirectory(first, firstZip, bundleOptions{reproducible: true, modTime: defaultBundleModTime})
+	require.NoError(t, err)
+
+	// Build the same content again with different timestamps and permissions.
+	second := filepath.Join(tmp, "second")
+	writeTestTree(t, second, files)
+	later := time.Now().Add(48 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(second, "index.html"), later, later))
+	require.NoError(t, os.Chmod(filepath.Join(second, "assets", "app.css"), 0600))
+	secondZip := filepath.Join(tmp, "second.zip")
+	_, err = zipDirectory(second, secondZip, bundleOptions{reproducible: true, modTime: defaultBundleModTime})
+	require.NoError(t, err)
+
+	assert.Equal(t, hashFile(t, firstZip), hashFile(t, secondZip))
+}
+
+func TestZipDirectory_ReproducibleEntries(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{
+		"b.txt":     "b",
+		"a.txt":     "a",
+		"dir/c.txt": "c",
+	})
+	require.NoError(t, os.Chmod(filepath.Join(src, "b.txt"), 0700))
+
+	modTime := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	zipPath := filepath.Join(tmp, "dist.zip")
+	_, err := zipDirectory(src, zipPath, bundleOptions{reproducible: true, modTime: modTime})
+	require.NoError(t, err)
+
+	reader, err := zip.OpenReader(zipPath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var names []string
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+		assert.True(t, f.Modified.Equal(modTime), "unexpected timestamp for %s: %s", f.Name, f.Modified)
+		switch f.Name {
+		case "dir/":
+			assert.Equal(t, os.ModeDir|0755, f.Mode())
+		case "b.txt":
+			assert.Equal(t, os.FileMode(0755), f.Mode())
+		default:
+			assert.Equal(t, os.FileMode(0644), f.Mode())
+		}
+	}
+	assert.Equal(t, []string{"a.txt", "b.txt", "dir/", "dir/c.txt"}, names)
+}
+
+func TestSourceDateEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	modTime, err := sourceDateEpoch()
+	require.NoError(t, err)
+	assert.Equal(t, defaultBundleModTime, modTime)
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	modTime, err = sourceDateEpoch()
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), modTime)
+
+	t.Setenv("SOURCE_DATE_EPOCH", "0")
+	modTime, err = sourceDateEpoch()
+	require.NoError(t, err)
+	assert.Equal(t, defaultBundleModTime, modTime)
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	_, err = sourceDateEpoch()
+	require.ErrorContains(t, err, "invalid SOURCE_DATE_EPOCH")
+}
+
+func zipEntryNames(t *testing.T, zipPath string) map[string]*zip.File {
+	t.Helper()
+	reader, err := zip.OpenReader(zipPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { reader.Close() })
+
+	files := map[string]*zip.File{}
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+	return files
+}
+
+func readZipEntry(t *testing.T, f *zip.File) string {
+	t.Helper()
+	rc, err := f.Open()
+	require.NoError(t, err)
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func skipWithoutSymlinks(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require elevated privileges on Windows")
+	}
+}
+
+func TestZipDirectory_SymlinksFollow(t *testing.T) {
+	skipWithoutSymlinks(t)
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{
+		"index.html":    "<html></html>",
+		"assets/app.js": "console.log('hi')",
+	})
+	require.NoError(t, os.Symlink("index.html", filepath.Join(src, "home.html")))
+	require.NoError(t, os.Symlink("assets", filepath.Join(src, "static")))
+
+	zipPath := filepath.Join(tmp, "dist.zip")
+	_, err := zipDirectory(src, zipPath, bundleOptions{symlinks: symlinksFollow})
+	require.NoError(t, err)
+
+	files := zipEntryNames(t, zipPath)
+	require.Contains(t, files, "home.html")
+	assert.Equal(t, "<html></html>", readZipEntry(t, files["home.html"]))
+	assert.True(t, files["home.html"].Mode().IsRegular())
+	require.Contains(t, files, "static/app.js")
+	assert.Equal(t, "console.log('hi')", readZipEntry(t, files["static/app.js"]))
+}
+
+func TestZipDirectory_SymlinksPreserve(t *testing.T) {
+	skipWithoutSymlinks(t)
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{"index.html": "<html></html>"})
+	require.NoError(t, os.Symlink("index.html", filepath.Join(src, "home.html")))
+
+	zipPath := filepath.Join(tmp, "dist.zip")
+	_, err := zipDirectory(src, zipPath, bundleOptions{symlinks: symlinksPreserve, reproducible: true, modTime: defaultBundleModTime})
+	require.NoError(t, err)
+
+	files := zipEntryNames(t, zipPath)
+	require.Contains(t, files, "home.html")
+	assert.Equal(t, os.ModeSymlink, files["home.html"].Mode().Type())
+	assert.Equal(t, "index.html", readZipEntry(t, files["home.html"]))
+}
+
+func TestZipDirectory_SymlinksError(t *testing.T) {
+	skipWithoutSymlinks(t)
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{"index.html": "<html></html>"})
+	require.NoError(t, os.Symlink("index.html", filepath.Join(src, "home.html")))
+
+	_, err := zipDirectory(src, filepath.Join(tmp, "dist.zip"), bundleOptions{symlinks: symlinksError})
+	require.ErrorContains(t, err, "is a symbolic link")
+}
+
+func TestZipDirectory_SymlinkOutsideRoot(t *testing.T) {
+	skipWithoutSymlinks(t)
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{"index.html": "<html></html>"})
+	writeTestTree(t, filepath.Join(tmp, "secrets"), map[string]string{"id_rsa": "private"})
+
+	for _, policy := range []string{symlinksFollow, symlinksPreserve} {
+		t.Run(policy, func(t *testing.T) {
+			link := filepath.Join(src, "leak")
+			require.NoError(t, os.Symlink(filepath.Join("..", "secrets"), link))
+			defer os.Remove(link)
+
+			_, err := zipDirectory(src, filepath.Join(tmp, "dist.zip"), bundleOptions{symlinks: policy})
+			require.ErrorContains(t, err, "outside of the deploy directory")
+		})
+	}
+}
+
+func TestZipDirectory_SymlinkCycle(t *testing.T) {
+	skipWithoutSymlinks(t)
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{"sub/index.html": "<html></html>"})
+	require.NoError(t, os.Symlink("..", filepath.Join(src, "sub", "loop")))
+
+	var warnings bytes.Buffer
+	zipPath := filepath.Join(tmp, "dist.zip")
+	_, err := zipDirectory(src, zipPath, bundleOptions{symlinks: symlinksFollow, warnings: &warnings})
+	require.NoError(t, err)
+	assert.Contains(t, warnings.String(), "symbolic link cycle")
+
+	files := zipEntryNames(t, zipPath)
+	assert.Contains(t, files, "sub/index.html")
+	assert.NotContains(t, files, "sub/loop/")
+}
+
+func TestZipDirectory_SkipsSpecialFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not supported on Windows")
+	}
+	// Unix socket paths are limited in length, so avoid the long t.TempDir().
+	tmp, err := os.MkdirTemp("", "bundle")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{"index.html": "<html></html>"})
+	listener, err := net.Listen("unix", filepath.Join(src, "app.sock"))
+	require.NoError(t, err)
+	defer listener.Close()
+
+	var warnings bytes.Buffer
+	zipPath := filepath.Join(tmp, "dist.zip")
+	_, err = zipDirectory(src, zipPath, bundleOptions{warnings: &warnings})
+	require.NoError(t, err)
+	assert.Contains(t, warnings.String(), "skipping")
+	assert.Contains(t, warnings.String(), "app.sock")
+
+	files := zipEntryNames(t, zipPath)
+	assert.Contains(t, files, "index.html")
+	assert.NotContains(t, files, "app.sock")
+}
+
+func TestNewBundleOptions_InvalidSymlinks(t *testing.T) {
+	_, err := newBundleOptions(false, "ignore")
+	require.ErrorContains(t, err, "invalid --symlinks value")
+}
+
+func TestZipDirectory_SkipsIgnoredNames(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "site")
+	writeTestTree(t, src, map[string]string{
+		"index.html":                "<html></html>",
+		".git/HEAD":                 "ref: refs/heads/main",
+		"node_modules/pkg/index.js": "module.exports = {}",
+		"assets/.DS_Store":          "",
+	})
+
+	zipPath := filepath.Join(tmp, "site.zip")
+	_, err := zipDirectory(src, zipPath, bundleOptions{skipProjectFiles: true})
+	require.NoError(t, err)
+
+	files := zipEntryNames(t, zipPath)
+	assert.Contains(t, files, "index.html")
+	assert.Contains(t, files, "assets/")
+	assert.NotContains(t, files, ".git/")
+	assert.NotContains(t, files, "node_modules/")
+	assert.NotContains(t, files, "assets/.DS_Store")
+
+	// Directories chosen explicitly are bundled as they are.
+	_, err = zipDirectory(src, zipPath, bundleOptions{})
+	require.NoError(t, err)
+
+	files = zipEntryNames(t, zipPath)
+	assert.Contains(t, files, ".git/HEAD")
+	assert.Contains(t, files, "node_modules/pkg/index.js")
+	assert.Contains(t, files, "assets/.DS_Store")
+}
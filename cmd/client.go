@@ -1,6 +1,9 @@
 package cmd
 
-import "io"
+import (
+	"io"
+	"net/http"
+)
 
 // restClient is the subset of api.RESTClient methods needed by the various commands.
 type restClient interface {
@@ -10,4 +13,5 @@ type restClient interface {
 	Patch(path string, body io.Reader, resp interface{}) error
 	Post(path string, body io.Reader, resp interface{}) error
 	Put(path string, body io.Reader, resp interface{}) error
+	Request(method string, path string, body io.Reader) (*http.Response, error)
 }
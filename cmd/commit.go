@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/github/gh-runtime-cli/internal/git"
+)
+
+// commitInfo describes the commit a deploy was made from.
+type commitInfo struct {
+	sha string
+	// branch and message are only resolved when git metadata is requested.
+	branch  string
+	message string
+}
+
+// resolveCommitInfo returns the commit being deployed from dir. An explicit
+// SHA takes precedence over GITHUB_SHA, which takes precedence over the HEAD
+// of the repository containing dir. If dir is not in a repository, the commit
+// is left empty. A SHA read from a working tree with uncommitted changes is
+// not used unless allowDirty is set, since the deployed files may not match
+// it.
+func resolveCommitInfo(dir, shaFlag string, allowDirty, withMetadata bool, warnings io.Writer) commitInfo {
+	repoDir := dir
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		// The directory may only be created by the build.
+		repoDir = "."
+	}
+
+	info := commitInfo{sha: shaFlag}
+	if info.sha == "" {
+		info.sha = os.Getenv("GITHUB_SHA")
+	}
+	if info.sha == "" {
+		sha, err := git.HeadSHA(repoDir)
+		if err != nil {
+			return commitInfo{}
+		}
+
+		dirty, err := git.IsDirty(repoDir)
+		if err != nil {
+			fmt.Fprintf(warnings, "warning: could not check for uncommitted changes: %v\n", err)
+		}
+		switch {
+		case dirty && !allowDirty:
+			fmt.Fprintf(warnings, "warning: the working tree has uncommitted changes, so the deploy is not tagged with commit %s (use --allow-dirty to tag it anyway)\n", shortSHA(sha))
+		case dirty:
+			fmt.Fprintf(warnings, "warning: the working tree has uncommitted changes, the deployed files may not match commit %s\n", shortSHA(sha))
+			info.sha = sha
+		default:
+			info.sha = sha
+		}
+	}
+
+	if !withMetadata {
+		return info
+	}
+
+	info.branch = os.Getenv("GITHUB_HEAD_REF")
+	if info.branch == "" && os.Getenv("GITHUB_REF_TYPE") == "branch" {
+		info.branch = os.Getenv("GITHUB_REF_NAME")
+	}
+	if info.branch == "" {
+		info.branch, _ = git.CurrentBranch(repoDir)
+	}
+	if info.sha != "" {
+		info.message, _ = git.CommitSubject(repoDir, info.sha)
+	}
+	return info
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-runtime-cli/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initTestRepo creates a repository with the given files committed on main
+// and returns its directory.
+func initTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	writeTestTree(t, dir, files)
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", "main"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "Add site"},
+	} {
+		_, err := git.Run(dir, args...)
+		require.NoError(t, err)
+	}
+	return dir
+}
+
+func TestResolveCommitInfo_FromGit(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
+	repo := initTestRepo(t, map[string]string{"dist/index.html": "hi"})
+	head, err := git.HeadSHA(repo)
+	require.NoError(t, err)
+
+	var warnings bytes.Buffer
+	info := resolveCommitInfo(filepath.Join(repo, "dist"), "", false, false, &warnings)
+	assert.Equal(t, commitInfo{sha: head}, info)
+	assert.Empty(t, warnings.String())
+}
+
+func TestResolveCommitInfo_Metadata(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
+	t.Setenv("GITHUB_HEAD_REF", "")
+	t.Setenv("GITHUB_REF_TYPE", "")
+	repo := initTestRepo(t, map[string]string{"index.html": "hi"})
+
+	info := resolveCommitInfo(repo, "", false, true, &bytes.Buffer{})
+	assert.Equal(t, "main", info.branch)
+	assert.Equal(t, "Add site", info.message)
+}
+
+func TestResolveCommitInfo_Dirty(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
+	repo := initTestRepo(t, map[string]string{"index.html": "hi"})
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "index.html"), []byte("changed"), 0644))
+
+	var warnings bytes.Buffer
+	info := resolveCommitInfo(repo, "", false, false, &warnings)
+	assert.Empty(t, info.sha)
+	assert.Contains(t, warnings.String(), "--allow-dirty")
+
+	warnings.Reset()
+	info = resolveCommitInfo(repo, "", true, false, &warnings)
+	assert.Len(t, info.sha, 40)
+	assert.Contains(t, warnings.String(), "may not match")
+}
+
+func TestResolveCommitInfo_Precedence(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{"index.html": "hi"})
+
+	t.Setenv("GITHUB_SHA", "from-actions")
+	assert.Equal(t, "from-actions", resolveCommitInfo(repo, "", false, false, &bytes.Buffer{}).sha)
+	assert.Equal(t, "from-flag", resolveCommitInfo(repo, "from-flag", false, false, &bytes.Buffer{}).sha)
+}
+
+func TestResolveCommitInfo_NotARepository(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
+
+	var warnings bytes.Buffer
+	info := resolveCommitInfo(t.TempDir(), "", false, true, &warnings)
+	assert.Equal(t, commitInfo{}, info)
+	assert.Empty(t, warnings.String())
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Modes of --compression.
+const (
+	// compressionAuto deflates entries, except for formats that are already
+	// compressed, which are stored.
+	compressionAuto = "auto"
+	// compressionStore stores every entry without compression.
+	compressionStore = "store"
+	// compressionDeflate deflates every entry.
+	compressionDeflate = "deflate"
+)
+
+// defaultPrecompressMinSize is the smallest file that precompressed variants
+// are generated for. Smaller files gain little from compression.
+const defaultPrecompressMinSize = "1KiB"
+
+// bundleCompression controls how the entries of a bundle are compressed. The
+// zero value deflates every entry at the default level.
+type bundleCompression struct {
+	// store keeps every entry uncompressed.
+	store bool
+	// auto stores entries that are already compressed, such as images and
+	// fonts.
+	auto bool
+	// level is the deflate level from 1 to 9, or 0 for the default level.
+	level int
+}
+
+// precompressedEncodings are the variants generated for compressible files,
+// keyed by the Content-Encoding they are served with.
+var precompressedEncodings = []struct {
+	encoding  string
+	extension string
+	compress  func(w io.Writer) io.WriteCloser
+}{
+	{"br", ".br", func(w io.Writer) io.WriteCloser { return brotli.NewWriterLevel(w, brotli.BestCompression) }},
+	{"gzip", ".gz", func(w io.Writer) io.WriteCloser {
+		gz, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+		return gz
+	}},
+}
+
+// compressedExtensions are formats that are already compressed and gain
+// nothing from being compressed again.
+var compressedExtensions = map[string]bool{
+	".7z": true, ".avif": true, ".br": true, ".bz2": true, ".gif": true, ".gz": true, ".heic": true,
+	".jpeg": true, ".jpg": true, ".m4a": true, ".mov": true, ".mp3": true, ".mp4": true, ".ogg": true,
+	".opus": true, ".pdf": true, ".png": true, ".webm": true, ".webp": true, ".woff": true, ".woff2": true,
+	".xz": true, ".zip": true, ".zst": true,
+}
+
+// compressibleExtensions are text and other formats that precompressed
+// variants are generated for.
+var compressibleExtensions = map[string]bool{
+	".cjs": true, ".css": true, ".csv": true, ".eot": true, ".htm": true, ".html": true, ".ico": true,
+	".js": true, ".json": true, ".map": true, ".md": true, ".mjs": true, ".otf": true, ".svg": true,
+	".ttf": true, ".txt": true, ".wasm": true, ".webmanifest": true, ".xhtml": true, ".xml": true,
+}
+
+// parseCompression parses a --compression value: 'auto', 'store' or
+// 'deflate', where 'auto' and 'deflate' take an optional level such as
+// 'deflate:9'.
+func parseCompression(value string) (bundleCompression, error) {
+	invalid := fmt.Errorf("invalid --compression value '%s': must be 'auto', 'store' or 'deflate', optionally with a level from 1 to 9 such as 'deflate:9'", value)
+
+	mode, levelValue, hasLevel := strings.Cut(value, ":")
+	compression := bundleCompression{}
+	switch mode {
+	case "", compressionAuto:
+		compression.auto = true
+	case compressionDeflate:
+	case compressionStore:
+		if hasLevel {
+			return compression, invalid
+		}
+		compression.store = true
+	default:
+		return compression, invalid
+	}
+
+	if hasLevel {
+		level, err := strconv.Atoi(levelValue)
+		if err != nil || level < flate.BestSpeed || level > flate.BestCompression {
+			return compression, invalid
+		}
+		compression.level = level
+	}
+	return compression, nil
+}
+
+// method returns the zip compression method for the entry at name.
+func (c bundleCompression) method(name string) uint16 {
+	if c.store || (c.auto && compressedExtensions[strings.ToLower(path.Ext(name))]) {
+		return zip.Store
+	}
+	return zip.Deflate
+}
+
+// register sets up zipWriter to deflate at the configured level.
+func (c bundleCompression) register(zipWriter *zip.Writer) {
+	if c.level == 0 {
+		return
+	}
+	zipWriter.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, c.level)
+	})
+}
+
+// precompressible reports whether variants should be generated for a file.
+func precompressible(name string, size, minSize int64) bool {
+	return size >= minSize && compressibleExtensions[strings.ToLower(path.Ext(name))]
+}
+
+// precompressEntry adds the precompressed variants of the file entry to the
+// bundle. Variants that would not be smaller than the file, or whose name is
+// taken by a file of the directory, are skipped. It returns the variants and
+// the encodings they are recorded under in the manifest of the file.
+func precompressEntry(zipWriter *zip.Writer, entry bundleEntry, taken map[string]bool, opts bundleOptions) ([]manifestFile, map[string]string, error) {
+	content, err := os.ReadFile(entry.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading file '%s': %w", entry.path, err)
+	}
+
+	variants := []manifestFile{}
+	encodings := map[string]string{}
+	for _, variant := range precompressedEncodings {
+		name := entry.name + variant.extension
+		if taken[name] {
+			opts.warnf("not precompressing '%s' as '%s' is already in the directory", entry.name, name)
+			continue
+		}
+
+		var compressed bytes.Buffer
+		writer := variant.compress(&compressed)
+		_, err = writer.Write(content)
+		if err == nil {
+			err = writer.Close()
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error compressing '%s': %w", entry.path, err)
+		}
+		if compressed.Len() >= len(content) {
+			continue
+		}
+
+		file, err := writeGeneratedFile(zipWriter, name, compressed.Bytes(), opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		variants = append(variants, file)
+		encodings[variant.encoding] = name
+	}
+	return variants, encodings, nil
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCompression(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bundleCompression
+	}{
+		{"", bundleCompression{auto: true}},
+		{"auto", bundleCompression{auto: true}},
+		{"auto:1", bundleCompression{auto: true, level: 1}},
+		{"store", bundleCompression{store: true}},
+		{"deflate", bundleCompression{}},
+		{"deflate:9", bundleCompression{level: 9}},
+	}
+	for _, tt := range tests {
+		got, err := parseCompression(tt.value)
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, got, tt.value)
+	}
+
+	for _, value := range []string{"zstd", "store:9", "deflate:0", "deflate:10", "deflate:fast"} {
+		_, err := parseCompression(value)
+		require.ErrorContains(t, err, "invalid --compression value '"+value+"'", value)
+	}
+}
+
+// zipMethods returns the compression method of every entry of a zip.
+func zipMethods(t *testing.T, zipPath string) map[string]uint16 {
+	t.Helper()
+	reader, err := zip.OpenReader(zipPath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	methods := map[string]uint16{}
+	for _, f := range reader.File {
+		methods[f.Name] = f.Method
+	}
+	return methods
+}
+
+func TestZipDirectory_Compression(t *testing.T) {
+	src := t.TempDir()
+	writeTestTree(t, src, map[string]string{
+		"index.html":      "<html></html>",
+		"images/logo.png": "\x89PNG",
+		"fonts/app.WOFF2": "wOF2",
+	})
+
+	tests := []struct {
+		compression string
+		want        map[string]uint16
+	}{
+		{"auto", map[string]uint16{"index.html": zip.Deflate, "images/logo.png": zip.Store, "fonts/app.WOFF2": zip.Store}},
+		{"store", map[string]uint16{"index.html": zip.Store, "images/logo.png": zip.Store, "fonts/app.WOFF2": zip.Store}},
+		{"deflate:9", map[string]uint16{"index.html": zip.Deflate, "images/logo.png": zip.Deflate, "fonts/app.WOFF2": zip.Deflate}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.compression, func(t *testing.T) {
+			compression, err := parseCompression(tt.compression)
+			require.NoError(t, err)
+			zipPath := filepath.Join(t.TempDir(), "bundle.zip")
+			_, err = zipDirectory(src, zipPath, bundleOptions{compression: compression})
+			require.NoError(t, err)
+
+			methods := zipMethods(t, zipPath)
+			for name, method := range tt.want {
+				assert.Equal(t, method, methods[name], name)
+			}
+		})
+	}
+}
+
+func TestZipDirectory_Precompress(t *testing.T) {
+	script := strings.Repeat("console.log('hello, world');\n", 200)
+	src := t.TempDir()
+	writeTestTree(t, src, map[string]string{
+		"index.html":       "<html></html>",
+		"assets/app.js":    script,
+		"assets/lib.js":    script,
+		"assets/lib.js.gz": "prebuilt",
+		"images/logo.png":  strings.Repeat("x", 4096),
+	})
+
+	var warnings bytes.Buffer
+	zipPath := filepath.Join(t.TempDir(), "bundle.zip")
+	_, err := zipDirectory(src, zipPath, bundleOptions{
+		compression:        bundleCompression{auto: true},
+		precompress:        true,
+		precompressMinSize: 1024,
+		manifest:           true,
+		reproducible:       true,
+		modTime:            defaultBundleModTime,
+		warnings:           &warnings,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "warning: not precompressing 'assets/lib.js' as 'assets/lib.js.gz' is already in the directory\n", warnings.String())
+
+	reader, err := zip.OpenReader(zipPath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	contents := map[string][]byte{}
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		contents[f.Name] = data
+	}
+
+	// Small files and formats that are already compressed are left alone.
+	assert.NotContains(t, contents, "index.html.gz")
+	assert.NotContains(t, contents, "images/logo.png.br")
+	assert.Equal(t, "prebuilt", string(contents["assets/lib.js.gz"]))
+	assert.Contains(t, contents, "assets/lib.js.br")
+
+	gz, err := gzip.NewReader(bytes.NewReader(contents["assets/app.js.gz"]))
+	require.NoError(t, err)
+	unzipped, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, script, string(unzipped))
+
+	unbrotlied, err := io.ReadAll(brotli.NewReader(bytes.NewReader(contents["assets/app.js.br"])))
+	require.NoError(t, err)
+	assert.Equal(t, script, string(unbrotlied))
+
+	methods := zipMethods(t, zipPath)
+	assert.Equal(t, zip.Store, methods["assets/app.js.gz"])
+	assert.Equal(t, zip.Store, methods["assets/app.js.br"])
+
+	manifest, err := readBundleManifest(&reader.Reader)
+	require.NoError(t, err)
+	encodings := map[string]map[string]string{}
+	for _, file := range manifest.Files {
+		if file.Encodings != nil {
+			encodings[file.Path] = file.Encodings
+		}
+	}
+	assert.Equal(t, map[string]map[string]string{
+		"assets/app.js": {"br": "assets/app.js.br", "gzip": "assets/app.js.gz"},
+		"assets/lib.js": {"br": "assets/lib.js.br"},
+	}, encodings)
+
+	result, err := verifyBundle(zipPath, bundleBudget{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Problems)
+}
+
+func TestRunDeploy_PrecompressWithArchive(t *testing.T) {
+	err := runDeploy(&mockRESTClient{}, deployCmdFlags{archive: "bundle.zip", app: "my-app", precompress: true})
+	require.EqualError(t, err, "--precompress cannot be used with --archive")
+}
+
+func TestRunDeploy_InvalidPrecompressMinSize(t *testing.T) {
+	src := t.TempDir()
+	writeTestTree(t, src, map[string]string{"index.html": "<html></html>"})
+
+	err := runDeploy(&mockRESTClient{}, deployCmdFlags{dir: src, app: "my-app", precompress: true, precompressMinSize: "1XB"})
+	require.ErrorContains(t, err, "invalid --precompress-min-size: invalid size '1XB'")
+}
@@ -8,7 +8,7 @@ import (
 	"strings"
 
 	"github.com/MakeNowJust/heredoc"
-	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/github/gh-runtime-cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -61,7 +61,7 @@ func init() {
 			# => Creates the app visible to 'my-org' organization
 		`),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := api.DefaultRESTClient()
+			client, err := newRESTClient()
 			if err != nil {
 				return fmt.Errorf("failed creating REST client: %v", err)
 			}
@@ -124,6 +124,7 @@ func runCreate(client restClient, flags createCmdFlags) (createResp, error) {
 		parts := strings.SplitN(pair, "=", 2)
 		if len(parts) == 2 {
 			requestBody.Secrets[parts[0]] = parts[1]
+			redactSecret(parts[1])
 		} else {
 			return createResp{}, fmt.Errorf("invalid secret format (%s). Must be in the form 'key=value'", pair)
 		}
@@ -152,14 +153,14 @@ func runCreate(client restClient, flags createCmdFlags) (createResp, error) {
 	response := createResp{}
 	err = client.Put(createUrl, bytes.NewReader(body), &response)
 	if err != nil {
-		return createResp{}, fmt.Errorf("error creating app: %v", err)
+		return createResp{}, fmt.Errorf("error creating app: %w", describeAPIError(err))
 	}
 
 	if flags.init {
 		if response.ID == "" {
 			return response, fmt.Errorf("error initializing config: server did not return an app ID")
 		}
-		err = writeRuntimeConfig(response.ID, "")
+		err = writeRuntimeConfig(config.RuntimeConfig{App: response.ID}, "")
 		if err != nil {
 			return response, fmt.Errorf("error initializing config: %v", err)
 		}
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// debugEnv turns on debug logging when set to anything but "", "0", "false"
+// or "no".
+const debugEnv = "GH_RUNTIME_DEBUG"
+
+// maxDebugBody is the number of bytes of a request or response body written to
+// the debug log. Longer bodies are truncated.
+const maxDebugBody = 4096
+
+// redactedValue replaces credentials and secret values in the debug log.
+const redactedValue = "[REDACTED]"
+
+// sensitiveHeaders are headers whose values are never written to the debug log.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+var debugFlags struct {
+	debug   bool
+	logFile string
+}
+
+// debugLog is where API requests are logged, or nil when debug logging is off.
+// It is opened by the first client created with newRESTClient.
+var debugLog struct {
+	once sync.Once
+	out  io.Writer
+	file *os.File
+	err  error
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&debugFlags.debug, "debug", false, fmt.Sprintf("Log API requests and responses to stderr, also enabled by setting %s", debugEnv))
+	rootCmd.PersistentFlags().StringVar(&debugFlags.logFile, "log-file", "", "Write the debug log to a file instead of stderr, implies --debug")
+}
+
+// debugEnabled reports whether API requests should be logged.
+func debugEnabled() bool {
+	if debugFlags.debug || debugFlags.logFile != "" {
+		return true
+	}
+	switch os.Getenv(debugEnv) {
+	case "", "0", "false", "no":
+		return false
+	}
+	return true
+}
+
+// openDebugLog returns the writer API requests are logged to, or nil when
+// debug logging is off.
+func openDebugLog() (io.Writer, error) {
+	debugLog.once.Do(func() {
+		if !debugEnabled() {
+			return
+		}
+		if debugFlags.logFile == "" {
+			debugLog.out = os.Stderr
+			return
+		}
+		file, err := os.OpenFile(debugFlags.logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			debugLog.err = fmt.Errorf("error opening log file: %v", err)
+			return
+		}
+		debugLog.out = file
+		debugLog.file = file
+	})
+	return debugLog.out, debugLog.err
+}
+
+// closeDebugLog closes the --log-file, if one was opened.
+func closeDebugLog() {
+	if debugLog.file != nil {
+		debugLog.file.Close()
+	}
+}
+
+// newRESTClient creates a client for the GitHub API that keeps the details of
+// error responses for describeAPIError, and logs its requests when debug
+// logging is on.
+func newRESTClient() (*api.RESTClient, error) {
+	out, err := openDebugLog()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport
+	if out != nil {
+		transport = &debugTransport{base: transport, out: out, redactor: debugRedactor}
+	}
+	return api.NewRESTClient(api.ClientOptions{
+		Transport: &errorDetailsTransport{base: transport},
+	})
+}
+
+// redactor replaces registered secret values with redactedValue.
+type redactor struct {
+	mu     sync.Mutex
+	values []string
+}
+
+// debugRedactor holds the secret values that must not appear in the debug log.
+var debugRedactor = &redactor{}
+
+// redactSecret keeps value out of the debug log, both as is and as it appears
+// inside a JSON string.
+func redactSecret(value string) {
+	debugRedactor.add(value)
+}
+
+func (r *redactor) add(value string) {
+	if value == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values = append(r.values, value)
+	if encoded, err := json.Marshal(value); err == nil {
+		if escaped := string(encoded[1 : len(encoded)-1]); escaped != value {
+			r.values = append(r.values, escaped)
+		}
+	}
+	// Replace longer values first, so that a value containing another is
+	// redacted as a whole.
+	slices.SortFunc(r.values, func(a, b string) int { return len(b) - len(a) })
+}
+
+func (r *redactor) redact(s string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, value := range r.values {
+		s = strings.ReplaceAll(s, value, redactedValue)
+	}
+	return s
+}
+
+// longest returns the length of the longest registered value.
+func (r *redactor) longest() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.values) == 0 {
+		return 0
+	}
+	return len(r.values[0])
+}
+
+// debugTransport logs every request and response to out.
+type debugTransport struct {
+	base     http.RoundTripper
+	out      io.Writer
+	redactor *redactor
+	mu       sync.Mutex
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var log bytes.Buffer
+	fmt.Fprintf(&log, "> %s %s\n", req.Method, t.redactor.redact(req.URL.String()))
+	t.writeHeaders(&log, ">", req.Header)
+	t.writeRequestBody(&log, req)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(&log, "! request failed after %s: %s\n\n", elapsed, t.redactor.redact(err.Error()))
+		t.write(log.Bytes())
+		return nil, err
+	}
+
+	fmt.Fprintf(&log, "< %s in %s\n", resp.Status, elapsed)
+	t.writeHeaders(&log, "<", resp.Header)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		fmt.Fprintf(&log, "! error reading response body: %v\n\n", err)
+		t.write(log.Bytes())
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.writeBody(&log, "<", body, int64(len(body)))
+	log.WriteString("\n")
+
+	t.write(log.Bytes())
+	return resp, nil
+}
+
+// write logs one request at a time, so that the logs of concurrent requests
+// are not interleaved.
+func (t *debugTransport) write(p []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.out.Write(p)
+}
+
+func (t *debugTransport) writeHeaders(log *bytes.Buffer, prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		for _, value := range header[name] {
+			if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+				// Keep the scheme, such as "token" or "Bearer", which helps
+				// tell which kind of credentials were sent.
+				scheme, _, ok := strings.Cut(value, " ")
+				value = redactedValue
+				if ok {
+					value = scheme + " " + redactedValue
+				}
+			}
+			fmt.Fprintf(log, "%s %s: %s\n", prefix, name, t.redactor.redact(value))
+		}
+	}
+}
+
+// writeRequestBody logs the body of req without consuming it. Bodies that
+// cannot be read again, such as streamed files, are not logged.
+func (t *debugTransport) writeRequestBody(log *bytes.Buffer, req *http.Request) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	if req.GetBody == nil {
+		fmt.Fprintf(log, "> [body not logged]\n")
+		return
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		fmt.Fprintf(log, "! error reading request body: %v\n", err)
+		return
+	}
+	defer body.Close()
+
+	// Read a little past the limit, so that a secret at the limit is still
+	// recognized and redacted.
+	data, err := io.ReadAll(io.LimitReader(body, int64(maxDebugBody+t.redactor.longest())))
+	if err != nil {
+		fmt.Fprintf(log, "! error reading request body: %v\n", err)
+		return
+	}
+	size := req.ContentLength
+	if size < int64(len(data)) {
+		size = int64(len(data))
+	}
+	t.writeBody(log, ">", data, size)
+}
+
+// writeBody logs a body of size bytes, of which data is the start. Binary
+// bodies are only logged by their size.
+func (t *debugTransport) writeBody(log *bytes.Buffer, prefix string, data []byte, size int64) {
+	if size == 0 {
+		return
+	}
+	truncated := int64(len(data)) < size
+	if isBinary(data, truncated) {
+		fmt.Fprintf(log, "%s [binary body, %d bytes]\n", prefix, size)
+		return
+	}
+
+	text := t.redactor.redact(string(data))
+	if truncated || len(text) > maxDebugBody {
+		text = strings.ToValidUTF8(text[:min(len(text), maxDebugBody)], "")
+		fmt.Fprintf(log, "%s %s\n%s [truncated, %d bytes]\n", prefix, text, prefix, size)
+		return
+	}
+	fmt.Fprintf(log, "%s %s\n", prefix, strings.TrimRight(text, "\n"))
+}
+
+// isBinary reports whether data is not text. When data is the start of a
+// longer body, its last character may be cut off.
+func isBinary(data []byte, truncated bool) bool {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	for i := 1; truncated && i < utf8.UTFMax && len(data) > 0 && !utf8.Valid(data); i++ {
+		data = data[:len(data)-1]
+	}
+	return !utf8.Valid(data)
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDebugTestServer(t *testing.T, status int, body string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-GitHub-Request-Id", "ABCD:1234")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDebugTransport_LogsRequestAndResponse(t *testing.T) {
+	server := newDebugTestServer(t, http.StatusUnprocessableEntity, `{"message":"Validation Failed"}`)
+
+	secrets := &redactor{}
+	secrets.add(`pa"ss`)
+	var log bytes.Buffer
+	client := &http.Client{Transport: &debugTransport{base: http.DefaultTransport, out: &log, redactor: secrets}}
+
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/runtime/my-app", strings.NewReader(`{"secrets":{"PASSWORD":"pa\"ss"}}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "token gho_abcdef")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// The response body is still readable after being logged.
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"message":"Validation Failed"}`, string(body))
+
+	output := log.String()
+	assert.Contains(t, output, "> PUT "+server.URL+"/runtime/my-app\n")
+	assert.Contains(t, output, "> Authorization: token [REDACTED]\n")
+	assert.Contains(t, output, `> {"secrets":{"PASSWORD":"[REDACTED]"}}`)
+	assert.Contains(t, output, "< 422 Unprocessable Entity in ")
+	assert.Contains(t, output, "< X-Github-Request-Id: ABCD:1234\n")
+	assert.Contains(t, output, `< {"message":"Validation Failed"}`)
+	assert.NotContains(t, output, "gho_abcdef")
+	assert.NotContains(t, output, `pa\"ss`)
+}
+
+func TestDebugTransport_TruncatesBody(t *testing.T) {
+	server := newDebugTestServer(t, http.StatusOK, strings.Repeat("a", maxDebugBody+100))
+
+	var log bytes.Buffer
+	client := &http.Client{Transport: &debugTransport{base: http.DefaultTransport, out: &log, redactor: &redactor{}}}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Len(t, body, maxDebugBody+100)
+	assert.Contains(t, log.String(), "< "+strings.Repeat("a", maxDebugBody)+"\n< [truncated, 4196 bytes]\n")
+}
+
+func TestDebugTransport_BinaryBody(t *testing.T) {
+	server := newDebugTestServer(t, http.StatusCreated, "")
+
+	var log bytes.Buffer
+	client := &http.Client{Transport: &debugTransport{base: http.DefaultTransport, out: &log, redactor: &redactor{}}}
+	resp, err := client.Post(server.URL, "application/zip", bytes.NewReader([]byte("PK\x03\x04\x00\x00")))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Contains(t, log.String(), "> [binary body, 6 bytes]\n")
+	assert.Contains(t, log.String(), "< 201 Created in ")
+}
+
+func TestDebugEnabled(t *testing.T) {
+	tests := []struct {
+		env      string
+		expected bool
+	}{
+		{env: "", expected: false},
+		{env: "0", expected: false},
+		{env: "false", expected: false},
+		{env: "1", expected: true},
+		{env: "api", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv(debugEnv, tt.env)
+			assert.Equal(t, tt.expected, debugEnabled())
+		})
+	}
+}
+
+func TestRunCreate_RedactsSecrets(t *testing.T) {
+	client := &mockRESTClient{
+		putFunc: func(path string, body io.Reader, resp interface{}) error {
+			buildCreateResponse(createResp{}, resp)
+			return nil
+		},
+	}
+	_, err := runCreate(client, createCmdFlags{app: "my-app", secrets: []string{"TOKEN=s3cr3t-value"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"TOKEN":"[REDACTED]"}`, debugRedactor.redact(`{"TOKEN":"s3cr3t-value"}`))
+}
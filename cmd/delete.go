@@ -5,7 +5,6 @@ import (
 	"net/url"
 
 	"github.com/MakeNowJust/heredoc"
-	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/spf13/cobra"
 )
 
@@ -27,7 +26,7 @@ func init() {
 			# => Deletes the app with ID 'my-app'
 		`),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := api.DefaultRESTClient()
+			client, err := newRESTClient()
 			if err != nil {
 				return fmt.Errorf("failed creating REST client: %v", err)
 			}
@@ -64,7 +63,7 @@ func runDelete(client restClient, flags deleteCmdFlags) (string, error) {
 	var response string
 	err := client.Delete(deleteUrl, &response)
 	if err != nil {
-		return response, fmt.Errorf("error deleting app: %v", err)
+		return response, fmt.Errorf("error deleting app: %w", describeAPIError(err))
 	}
 
 	// Actual response on success is empty body so return the ID
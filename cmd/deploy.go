@@ -1,26 +1,48 @@
 package cmd
 
 import (
-	"archive/zip"
 	"bytes"
+	"crypto/ed25519"
 	"fmt"
-	"io"
-	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
-	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/text"
 	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/github/gh-runtime-cli/internal/framework"
+	"github.com/github/gh-runtime-cli/internal/provenance"
+	"github.com/github/gh-runtime-cli/internal/routes"
+	"github.com/github/gh-runtime-cli/internal/sbom"
+	"github.com/github/gh-runtime-cli/internal/secrets"
 	"github.com/spf13/cobra"
 )
 
 type deployCmdFlags struct {
-	dir          string
-	app          string
-	revisionName string
-	sha          string
-	config       string
+	dir                string
+	app                string
+	revisionName       string
+	sha                string
+	config             string
+	reproducible       bool
+	symlinks           string
+	maxSize            string
+	maxFileSize        string
+	dryRun             bool
+	buildCmd           string
+	skipBuild          bool
+	watch              bool
+	allowDirty         bool
+	gitMetadata        bool
+	ref                string
+	archive            string
+	signingKey         string
+	sbom               bool
+	compression        string
+	precompress        bool
+	precompressMinSize string
 }
 
 func init() {
@@ -28,11 +50,44 @@ func init() {
 	deployCmd := &cobra.Command{
 		Use:   "deploy",
 		Short: "Deploy app to GitHub Runtime",
-		Long: heredoc.Doc(`
+		Long: heredoc.Docf(`
 			Deploys a directory to a GitHub Runtime app.
+			If --dir is not given, the directory is read from 'dir' in the runtime config file, or inferred
+			along with the build command from a Vite, Next.js static export, Create React App, Astro,
+			SvelteKit static or plain index.html project in the current directory. Version control,
+			node_modules, .DS_Store and Thumbs.db files are left out of inferred directories, which can be the
+			project itself; a directory given with --dir or 'dir' is deployed as it is.
 			You can specify the app ID using --app flag, --config flag to read from a runtime config file,
 			or it will automatically read from runtime.config.json in the current directory if it exists.
-		`),
+			Symbolic links are followed by default and links that point outside of the directory are rejected.
+			If a build command is configured with --build-cmd or 'build' in the runtime config file, it is run
+			before bundling with GH_RUNTIME_APP_ID, GH_RUNTIME_REVISION_NAME and GH_RUNTIME_REVISION set.
+			If --sha is not given, it is read from GITHUB_SHA or from the commit checked out in the repository
+			of the directory. A commit is not read from a working tree with uncommitted changes unless
+			--allow-dirty is set.
+			With --ref, the tree of a git ref is exported from the current repository into a temporary
+			directory and built and deployed from there with the SHA of the ref, ignoring local changes.
+			The runtime config file, --dir and --config are then read relative to the exported tree.
+			With --archive, a prebuilt zip, tar or tar.gz archive is validated and deployed instead of a
+			directory. Zip archives are uploaded as they are and tar archives are converted to zip.
+			If a signing key is given with --signing-key or GH_RUNTIME_SIGNING_KEY, a SLSA provenance
+			statement describing the bundle and its build is signed and uploaded along with the bundle.
+			With --sbom, an SPDX SBOM listing every file of the bundle and the npm packages locked by the
+			package-lock.json, npm-shrinkwrap.json or yarn.lock of the project is uploaded along with the bundle.
+			Before it is uploaded, the bundle is scanned for GitHub tokens, AWS keys, private keys and .env
+			files, and the deploy is blocked if any are found. Files that are known not to hold secrets can
+			be ignored with 'secretScan.ignore' in the runtime config file, e.g.
+			{"secretScan": {"ignore": [{"path": "docs/**", "rule": "private-key"}]}}.
+			Redirects, rewrites and response headers are read from 'routes' and 'headers' in the runtime
+			config file and from _redirects and _headers files at the root of the directory, and 'spaFallback'
+			serves index.html for routes that do not match a file. They are validated and added to the bundle
+			at %[1]s%[2]s%[1]s. Bundles deployed with --archive are deployed as they are.
+			With --precompress, gzip (.gz) and brotli (.br) variants of HTML, CSS, JavaScript, JSON, SVG, font
+			and other text assets of at least --precompress-min-size are added next to them, and recorded in
+			the 'encodings' of their entry in the bundle manifest, so that they are served without compressing
+			them on every request. Zip entries are deflated except for formats that are already compressed,
+			such as images, fonts and archives, which are stored; use --compression to change this.
+		`, "`", bundleRoutesPath),
 		Example: heredoc.Doc(`
 			$ gh runtime deploy --dir ./dist --app my-app [--sha <sha>]
 			# => Deploys the contents of the 'dist' directory to the app with ID 'my-app'.
@@ -42,142 +97,441 @@ func init() {
 			
 			$ gh runtime deploy --dir ./dist
 			# => Deploys using app ID from runtime.config.json in current directory (if it exists).
+
+			$ gh runtime deploy
+			# => Detects the project framework, builds it and deploys its output directory.
+
+			$ gh runtime deploy --dir ./dist --build-cmd "npm run build"
+			# => Runs 'npm run build' and then deploys the contents of the 'dist' directory.
+
+			$ gh runtime deploy --ref v1.4.2
+			# => Builds and deploys the tree of the 'v1.4.2' tag, without any uncommitted local changes.
+
+			$ gh runtime deploy --archive dist.tar.gz --app my-app
+			# => Deploys the contents of a prebuilt archive without building or zipping a directory.
+
+			$ gh runtime deploy --watch --revision-name preview
+			# => Rebuilds and redeploys to the 'preview' revision every time the project changes.
+
+			$ gh runtime deploy --precompress --compression deflate:9
+			# => Adds gzip and brotli variants of text assets and compresses the bundle as much as possible.
+
+			$ gh runtime deploy --dir ./dist --max-size 25MB --max-file-size 5MB --dry-run
+			# => Builds the bundle, prints its largest files and directories and checks the size limits without deploying.
 		`),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := api.DefaultRESTClient()
+			client, err := newRESTClient()
 			if err != nil {
 				return fmt.Errorf("error creating REST client: %v", err)
 			}
 
+			if deployCmdFlags.watch {
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+				defer stop()
+				return runWatch(ctx, client, deployCmdFlags)
+			}
+
+			if deployCmdFlags.ref != "" {
+				return runDeployRef(client, deployCmdFlags)
+			}
+
 			return runDeploy(client, deployCmdFlags)
 		},
 	}
-	deployCmd.Flags().StringVarP(&deployCmdFlags.dir, "dir", "d", "", "The directory to deploy")
-	deployCmd.Flags().StringVarP(&deployCmdFlags.app, "app", "a", "", "The app ID to deploy")
-	deployCmd.Flags().StringVarP(&deployCmdFlags.config, "config", "c", "", "Path to runtime config file")
+	addDeployFlags(deployCmd, &deployCmdFlags)
 	deployCmd.Flags().StringVarP(&deployCmdFlags.revisionName, "revision-name", "r", "", "The revision name to deploy")
-	deployCmd.Flags().StringVarP(&deployCmdFlags.sha, "sha", "s", "", "SHA of the app being deployed")
+	deployCmd.Flags().BoolVarP(&deployCmdFlags.watch, "watch", "w", false, "Keep running and redeploy to --revision-name whenever the app changes")
+	deployCmd.Flags().StringVar(&deployCmdFlags.ref, "ref", "", "Deploy the tree of a git ref, such as a tag or commit, instead of the working tree")
 
 	rootCmd.AddCommand(deployCmd)
 }
 
-func runDeploy(client restClient, flags deployCmdFlags) error {
-	if flags.dir == "" {
-		return fmt.Errorf("--dir flag is required")
-	}
+// addDeployFlags registers the flags shared by every command that deploys a
+// directory. The revision name is left to each command.
+func addDeployFlags(cmd *cobra.Command, flags *deployCmdFlags) {
+	addBundleFlags(cmd, flags)
+	cmd.Flags().StringVarP(&flags.app, "app", "a", "", "The app ID to deploy")
+	cmd.Flags().StringVarP(&flags.sha, "sha", "s", "", "SHA of the app being deployed (defaults to GITHUB_SHA or the current git commit)")
+	cmd.Flags().BoolVar(&flags.allowDirty, "allow-dirty", false, "Use the current git commit as the SHA even if the working tree has uncommitted changes")
+	cmd.Flags().BoolVar(&flags.gitMetadata, "git-metadata", false, "Send the git branch and commit message along with the bundle")
+	cmd.Flags().StringVar(&flags.archive, "archive", "", "Deploy a prebuilt zip, tar or tar.gz archive instead of a directory ('-' reads it from stdin)")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Build the bundle and print a size report without deploying")
+}
+
+// addBundleFlags registers the flags that control how a directory is built
+// and bundled.
+func addBundleFlags(cmd *cobra.Command, flags *deployCmdFlags) {
+	cmd.Flags().StringVarP(&flags.dir, "dir", "d", "", "The directory to deploy")
+	cmd.Flags().StringVarP(&flags.config, "config", "c", "", "Path to runtime config file")
+	cmd.Flags().BoolVar(&flags.reproducible, "reproducible", true, "Produce a byte-identical bundle for identical content (honors SOURCE_DATE_EPOCH)")
+	cmd.Flags().StringVar(&flags.symlinks, "symlinks", symlinksFollow, "How to bundle symbolic links: 'follow', 'preserve' or 'error'")
+	cmd.Flags().StringVar(&flags.maxSize, "max-size", "", "Fail if the bundle is larger than this size (e.g. '25MB')")
+	cmd.Flags().StringVar(&flags.maxFileSize, "max-file-size", "", "Fail if any file is larger than this size (e.g. '5MB')")
+	cmd.Flags().StringVar(&flags.buildCmd, "build-cmd", "", "Command to run before bundling (overrides 'build' in the runtime config file)")
+	cmd.Flags().BoolVar(&flags.skipBuild, "skip-build", false, "Do not run the build command before bundling")
+	cmd.Flags().StringVar(&flags.signingKey, "signing-key", "", "Path to a PEM encoded ed25519 private key to sign build provenance with (defaults to "+signingKeyEnv+")")
+	cmd.Flags().StringVar(&flags.compression, "compression", compressionAuto, "How to compress zip entries: 'auto', 'store' or 'deflate', with an optional level such as 'deflate:9'")
+	cmd.Flags().BoolVar(&flags.precompress, "precompress", false, "Add gzip and brotli compressed variants of text assets to the bundle")
+	cmd.Flags().StringVar(&flags.precompressMinSize, "precompress-min-size", defaultPrecompressMinSize, "Smallest file to add compressed variants of with --precompress")
+	cmd.Flags().BoolVar(&flags.sbom, "sbom", false, "Generate an SPDX SBOM of the bundle files and the npm packages of the project")
+}
 
-	appName, err := config.ResolveAppName(flags.app, flags.config)
+// deployment is a deploy whose app, directory, build command and bundle
+// settings have been resolved from flags, the runtime config file and the
+// project.
+type deployment struct {
+	flags   deployCmdFlags
+	appName string
+	// root is the directory the deploy is resolved and built in.
+	root       string
+	commit     commitInfo
+	budget     bundleBudget
+	bundleOpts bundleOptions
+	// signingKey signs the provenance of the bundle. No provenance is produced
+	// if it is nil.
+	signingKey ed25519.PrivateKey
+	// scanner checks the bundle for secrets before it is uploaded.
+	scanner *secrets.Scanner
+	// routes are the redirects, rewrites and headers of the runtime config
+	// file, which are added to the bundle.
+	routes routes.Config
+}
+
+func runDeploy(client restClient, flags deployCmdFlags) error {
+	d, err := resolveDeployment(flags)
 	if err != nil {
 		return err
 	}
 
-	if _, err := os.Stat(flags.dir); os.IsNotExist(err) {
-		return fmt.Errorf("directory '%s' does not exist", flags.dir)
-	}
+	return d.run(client)
+}
 
-	_, err = os.ReadDir(flags.dir)
+// resolveDeployment validates the flags and resolves everything needed to run
+// a deploy from the current directory.
+func resolveDeployment(flags deployCmdFlags) (deployment, error) {
+	return resolveDeploymentIn(".", flags)
+}
+
+// resolveDeploymentIn resolves a deploy as if it was run from root: the
+// runtime config file, the directory and the build command are all looked up
+// relative to it.
+func resolveDeploymentIn(root string, flags deployCmdFlags) (deployment, error) {
+	d, err := resolveBundleIn(root, flags)
 	if err != nil {
-		return fmt.Errorf("error reading directory '%s': %v", flags.dir, err)
+		return deployment{}, err
 	}
 
-	zipPath := fmt.Sprintf("%s.zip", flags.dir)
-	err = zipDirectory(flags.dir, zipPath)
+	d.appName, err = config.ResolveAppName(d.flags.app, d.flags.config)
 	if err != nil {
-		return fmt.Errorf("error zipping directory '%s': %v", flags.dir, err)
+		return deployment{}, err
 	}
-	defer os.Remove(zipPath)
 
-	deploymentsUrl := fmt.Sprintf("runtime/%s/deployment/bundle", appName)
-	params := url.Values{}
+	return d, nil
+}
+
+// resolveBundleIn resolves everything needed to build a bundle from root,
+// without the app it is deployed to.
+func resolveBundleIn(root string, flags deployCmdFlags) (deployment, error) {
+	flags.config = resolveConfigPathIn(root, flags.config)
 
-	if flags.revisionName != "" {
-		params.Add("revision_name", flags.revisionName)
+	runtimeConfig, err := config.LoadRuntimeConfig(flags.config)
+	if err != nil {
+		return deployment{}, err
+	}
+
+	inferredDir := false
+	if flags.archive != "" {
+		if flags.dir != "" {
+			return deployment{}, fmt.Errorf("--dir cannot be used with --archive")
+		}
+		if flags.buildCmd != "" {
+			return deployment{}, fmt.Errorf("--build-cmd cannot be used with --archive")
+		}
+		if flags.precompress {
+			return deployment{}, fmt.Errorf("--precompress cannot be used with --archive")
+		}
+	} else {
+		flags.dir, flags.buildCmd, inferredDir, err = resolveDeployDir(root, flags, runtimeConfig)
+		if err != nil {
+			return deployment{}, err
+		}
 	}
 
-	if flags.sha != "" {
-		params.Add("revision", flags.sha)
+	commit := resolveCommitInfo(flags.dir, flags.sha, flags.allowDirty, flags.gitMetadata, os.Stderr)
+	flags.sha = commit.sha
+
+	budget, err := resolveBundleBudget(flags.maxSize, flags.maxFileSize, runtimeConfig.MaxSize, runtimeConfig.MaxFileSize)
+	if err != nil {
+		return deployment{}, err
 	}
 
-	if len(params) > 0 {
-		deploymentsUrl += "?" + params.Encode()
+	bundleOpts, err := newBundleOptions(flags.reproducible, flags.symlinks)
+	if err != nil {
+		return deployment{}, err
+	}
+	bundleOpts.skipProjectFiles = inferredDir
+	bundleOpts.compression, err = parseCompression(flags.compression)
+	if err != nil {
+		return deployment{}, err
+	}
+	if flags.precompress {
+		minSize := flags.precompressMinSize
+		if minSize == "" {
+			minSize = defaultPrecompressMinSize
+		}
+		bundleOpts.precompress = true
+		bundleOpts.precompressMinSize, err = parseByteSize(minSize)
+		if err != nil {
+			return deployment{}, fmt.Errorf("invalid --precompress-min-size: %v", err)
+		}
 	}
 
-	fmt.Printf("Deploying app to %s\n", deploymentsUrl)
+	signingKey, err := loadSigningKey(flags.signingKey)
+	if err != nil {
+		return deployment{}, err
+	}
 
-	body, err := os.ReadFile(zipPath)
+	scanner, err := newSecretScanner(runtimeConfig.SecretScan)
 	if err != nil {
-		return fmt.Errorf("error reading zip file '%s': %v", zipPath, err)
+		return deployment{}, err
 	}
 
-	err = client.Post(deploymentsUrl, bytes.NewReader(body), nil)
+	routesConfig, err := configRoutes(runtimeConfig)
 	if err != nil {
-		return fmt.Errorf("error deploying app: %v", err)
+		return deployment{}, err
 	}
 
-	fmt.Printf("Successfully deployed app\n")
-	return nil
+	return deployment{flags: flags, root: root, signingKey: signingKey, scanner: scanner, routes: routesConfig, commit: commit, budget: budget, bundleOpts: bundleOpts}, nil
 }
 
-func zipDirectory(sourceDir, destinationZip string) error {
-	zipFile, err := os.Create(destinationZip)
+// run builds and bundles the app, or prepares its prebuilt archive, and
+// uploads the bundle.
+func (d deployment) run(client restClient) error {
+	flags := d.flags
+
+	var zipPath string
+	var entries []bundleEntry
+	var cleanup func()
+	var err error
+	startedOn := time.Now()
+	if flags.archive != "" {
+		zipPath, entries, cleanup, err = prepareArchive(flags.archive, os.Stdin)
+	} else {
+		zipPath, entries, cleanup, err = d.bundleDir()
+	}
 	if err != nil {
-		return fmt.Errorf("error creating zip file '%s': %w", destinationZip, err)
+		return err
 	}
-	defer zipFile.Close()
+	defer cleanup()
+	finishedOn := time.Now()
 
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
+	zipInfo, err := os.Stat(zipPath)
+	if err != nil {
+		return fmt.Errorf("error reading zip file '%s': %v", zipPath, err)
+	}
 
-	err = filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+	report := newSizeReport(entries, zipInfo.Size())
+	if flags.dryRun {
+		report.write(os.Stdout, sizeReportTopN)
+		fmt.Printf("Dry run: bundle for app '%s' was not deployed\n", d.appName)
+		err = d.budget.check(report)
 		if err != nil {
-			return fmt.Errorf("error accessing path '%s': %w", path, err)
+			return err
 		}
+		return d.checkSecrets(zipPath, os.Stderr)
+	}
 
-		relPath, err := filepath.Rel(sourceDir, path)
+	err = d.budget.check(report)
+	if err != nil {
+		report.write(os.Stderr, sizeReportTopN)
+		return err
+	}
+
+	err = d.checkSecrets(zipPath, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	var envelope provenance.Envelope
+	if d.signingKey != nil {
+		envelope, err = d.signProvenance(zipPath, "bundle.zip", startedOn, finishedOn)
 		if err != nil {
-			return fmt.Errorf("error calculating relative path for '%s': %w", path, err)
+			return err
 		}
+	}
 
-		if info.IsDir() {
-			if relPath == "." {
-				return nil
-			}
-			relPath += "/"
+	var bom sbom.Document
+	if flags.sbom {
+		bom, err = d.generateSBOM(zipPath, "bundle.zip")
+		if err != nil {
+			return err
 		}
+	}
+
+	params := revisionQuery(flags.revisionName, flags.sha)
+
+	if d.commit.branch != "" {
+		params.Add("branch", d.commit.branch)
+	}
+
+	if d.commit.message != "" {
+		params.Add("commit_message", d.commit.message)
+	}
+
+	deploymentsUrl := deploymentURL(d.appName, "bundle", params)
+
+	fmt.Printf("Deploying app to %s\n", deploymentsUrl)
+
+	body, err := os.ReadFile(zipPath)
+	if err != nil {
+		return fmt.Errorf("error reading zip file '%s': %v", zipPath, err)
+	}
+
+	err = client.Post(deploymentsUrl, bytes.NewReader(body), nil)
+	if err != nil {
+		return fmt.Errorf("error deploying app: %w", describeAPIError(err))
+	}
 
-		header, err := zip.FileInfoHeader(info)
+	if d.signingKey != nil {
+		err = d.uploadProvenance(client, envelope)
 		if err != nil {
-			return fmt.Errorf("error creating zip header for '%s': %w", path, err)
-		}
-		header.Name = relPath
-		if !info.IsDir() {
-			header.Method = zip.Deflate
+			return err
 		}
+		fmt.Printf("Uploaded signed provenance\n")
+	}
 
-		writer, err := zipWriter.CreateHeader(header)
+	if flags.sbom {
+		err = d.uploadSBOM(client, bom)
 		if err != nil {
-			return fmt.Errorf("error creating zip writer for '%s': %w", path, err)
+			return err
 		}
+		fmt.Printf("Uploaded SBOM (%s, %s)\n", text.Pluralize(len(bom.Files), "file"), text.Pluralize(bom.Libraries(), "npm package"))
+	}
 
-		if !info.IsDir() {
-			file, err := os.Open(path)
-			if err != nil {
-				return fmt.Errorf("error opening file '%s': %w", path, err)
-			}
-			defer file.Close()
+	fmt.Printf("Successfully deployed app\n")
+	return nil
+}
 
-			_, err = io.Copy(writer, file)
-			if err != nil {
-				return fmt.Errorf("error writing file '%s' to zip: %w", path, err)
-			}
+// bundleDir writes the bundle of the app to a temporary file. It returns the
+// zip file, its entries and a function that removes it.
+func (d deployment) bundleDir() (string, []bundleEntry, func(), error) {
+	zipFile, err := os.CreateTemp("", "gh-runtime-bundle-*.zip")
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("error creating zip file: %v", err)
+	}
+	zipPath := zipFile.Name()
+	zipFile.Close()
+	cleanup := func() { os.Remove(zipPath) }
+
+	entries, err := d.writeBundle(zipPath)
+	if err != nil {
+		cleanup()
+		return "", nil, nil, err
+	}
+
+	return zipPath, entries, cleanup, nil
+}
+
+// writeBundle builds the app if a build command is configured and zips the
+// directory to zipPath.
+func (d deployment) writeBundle(zipPath string) ([]bundleEntry, error) {
+	flags := d.flags
+
+	if flags.buildCmd != "" && !flags.skipBuild {
+		err := runBuildCommand(flags.buildCmd, d.root, buildEnv(d.appName, flags.revisionName, flags.sha), os.Stdout, os.Stderr)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		return nil
-	})
+	if _, err := os.Stat(flags.dir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory '%s' does not exist", flags.dir)
+	}
 
+	_, err := os.ReadDir(flags.dir)
 	if err != nil {
-		return fmt.Errorf("error zipping directory '%s': %w", sourceDir, err)
+		return nil, fmt.Errorf("error reading directory '%s': %v", flags.dir, err)
 	}
 
-	return nil
+	routesConfig, err := loadRoutes(d.routes, flags.dir)
+	if err != nil {
+		return nil, err
+	}
+	opts := d.bundleOpts
+	if !routesConfig.IsEmpty() {
+		opts.routes = &routesConfig
+	}
+
+	entries, err := zipDirectory(flags.dir, zipPath, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error zipping directory '%s': %v", flags.dir, err)
+	}
+	return entries, nil
+}
+
+// resolveDeployDir returns the directory to deploy and the build command to run
+// before bundling, and whether the directory was inferred. Flags take
+// precedence over the runtime config file. If neither specifies a directory,
+// both are inferred from the framework detected in root. Relative directories
+// are relative to root.
+func resolveDeployDir(root string, flags deployCmdFlags, runtimeConfig config.RuntimeConfig) (string, string, bool, error) {
+	dir := flags.dir
+	if dir == "" {
+		dir = runtimeConfig.Dir
+	}
+	buildCmd := flags.buildCmd
+	if buildCmd == "" {
+		buildCmd = runtimeConfig.Build
+	}
+	if dir != "" {
+		return pathIn(root, dir), buildCmd, false, nil
+	}
+
+	detected, err := framework.Detect(root)
+	if err != nil {
+		return "", "", false, fmt.Errorf("error detecting project framework: %v", err)
+	}
+	if detected == nil {
+		return "", "", false, fmt.Errorf("--dir flag is required: no supported framework was detected in the current directory")
+	}
+
+	if buildCmd == "" {
+		buildCmd = detected.BuildCommand
+	}
+	printDetectedFramework(detected, buildCmd)
+	return pathIn(root, detected.OutputDir), buildCmd, true, nil
+}
+
+// resolveConfigPathIn returns the runtime config file to use when running
+// from root. An empty path stands for the default config file in root, or in
+// the current directory if root does not have one.
+func resolveConfigPathIn(root, configPath string) string {
+	if root == "." {
+		return configPath
+	}
+	if configPath == "" {
+		defaultPath := filepath.Join(root, config.DefaultConfigPath)
+		if _, err := os.Stat(defaultPath); err != nil {
+			return ""
+		}
+		return defaultPath
+	}
+	return pathIn(root, configPath)
+}
+
+// pathIn resolves a relative path against root.
+func pathIn(root, p string) string {
+	if root == "." || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(root, p)
+}
+
+// printDetectedFramework reports what was inferred from the project.
+func printDetectedFramework(detected *framework.Framework, buildCmd string) {
+	if buildCmd != "" {
+		fmt.Printf("Detected %s project: using directory '%s' built with '%s'\n", detected.Name, detected.OutputDir, buildCmd)
+	} else {
+		fmt.Printf("Detected %s project: using directory '%s'\n", detected.Name, detected.OutputDir)
+	}
 }
@@ -50,6 +50,7 @@ func TestRunDeploy_DirNotExist(t *testing.T) {
 }
 
 func TestRunDeploy_Success(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
 	tmp := t.TempDir()
 	origDir, err := os.Getwd()
 	require.NoError(t, err)
@@ -144,3 +145,208 @@ func TestRunDeploy_WithConfigFile(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, capturedPath, "config-deploy-app")
 }
+
+func TestRunDeploy_ExceedsMaxFileSize(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	deployDir := filepath.Join(tmp, "dist")
+	require.NoError(t, os.MkdirAll(deployDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(deployDir, "index.html"), []byte("<html></html>"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(deployDir, "video.mp4"), make([]byte, 4096), 0644))
+
+	posted := false
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			posted = true
+			return nil
+		},
+	}
+
+	err = runDeploy(client, deployCmdFlags{dir: deployDir, app: "my-app", maxFileSize: "1KiB"})
+	require.ErrorContains(t, err, "file 'video.mp4'")
+	assert.False(t, posted, "bundle should not be uploaded when it exceeds its size budget")
+}
+
+func TestRunDeploy_MaxSizeFromConfig(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	deployDir := filepath.Join(tmp, "dist")
+	require.NoError(t, os.MkdirAll(deployDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(deployDir, "index.html"), []byte("<html></html>"), 0644))
+	require.NoError(t, os.WriteFile("runtime.config.json", []byte(`{"app":"my-app","maxSize":"10B"}`), 0644))
+
+	client := &mockRESTClient{
+		postFunc: mockPostSuccess(),
+	}
+
+	err = runDeploy(client, deployCmdFlags{dir: deployDir})
+	require.ErrorContains(t, err, "exceeds the maximum of 10 B")
+
+	err = runDeploy(client, deployCmdFlags{dir: deployDir, maxSize: "1MB"})
+	require.NoError(t, err)
+}
+
+func TestRunDeploy_DryRun(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	deployDir := filepath.Join(tmp, "dist")
+	require.NoError(t, os.MkdirAll(deployDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(deployDir, "index.html"), []byte("<html></html>"), 0644))
+
+	client := &mockRESTClient{}
+	err = runDeploy(client, deployCmdFlags{dir: deployDir, app: "my-app", dryRun: true})
+	require.NoError(t, err)
+	require.NoFileExists(t, deployDir+".zip")
+}
+
+func TestRunDeploy_RunsBuildCommand(t *testing.T) {
+	skipWithoutShell(t)
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	require.NoError(t, os.WriteFile("runtime.config.json", []byte(`{"app":"my-app","build":"mkdir -p dist && echo $GH_RUNTIME_APP_ID > dist/app.txt"}`), 0644))
+
+	var capturedBody []byte
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			capturedBody, _ = io.ReadAll(body)
+			return nil
+		},
+	}
+
+	err = runDeploy(client, deployCmdFlags{dir: "dist"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, capturedBody)
+
+	data, err := os.ReadFile(filepath.Join("dist", "app.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "my-app\n", string(data))
+}
+
+func TestRunDeploy_BuildCommandFailure(t *testing.T) {
+	skipWithoutShell(t)
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	require.NoError(t, os.MkdirAll("dist", 0755))
+
+	posted := false
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			posted = true
+			return nil
+		},
+	}
+
+	err = runDeploy(client, deployCmdFlags{dir: "dist", app: "my-app", buildCmd: "exit 1"})
+	require.ErrorContains(t, err, "build command 'exit 1' failed")
+	assert.False(t, posted)
+
+	err = runDeploy(client, deployCmdFlags{dir: "dist", app: "my-app", buildCmd: "exit 1", skipBuild: true})
+	require.NoError(t, err)
+	assert.True(t, posted)
+}
+
+func TestRunDeploy_DetectsFramework(t *testing.T) {
+	skipWithoutShell(t)
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	require.NoError(t, os.WriteFile("package.json", []byte(`{"scripts":{"build":"vite build"},"devDependencies":{"vite":"^5.0.0"}}`), 0644))
+
+	posted := false
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			posted = true
+			return nil
+		},
+	}
+
+	// The detected build command is overridden so the test does not need npm.
+	err = runDeploy(client, deployCmdFlags{app: "my-app", buildCmd: "mkdir -p dist && touch dist/index.html"})
+	require.NoError(t, err)
+	assert.True(t, posted)
+	assert.FileExists(t, filepath.Join("dist", "index.html"))
+}
+
+func TestRunDeploy_DirFromConfig(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	require.NoError(t, os.MkdirAll("public", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("public", "index.html"), []byte("<html></html>"), 0644))
+	require.NoError(t, os.WriteFile("runtime.config.json", []byte(`{"app":"my-app","dir":"public"}`), 0644))
+
+	var capturedPath string
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			capturedPath = path
+			return nil
+		},
+	}
+
+	err = runDeploy(client, deployCmdFlags{})
+	require.NoError(t, err)
+	assert.Equal(t, "runtime/my-app/deployment/bundle", capturedPath)
+}
+
+func TestRunDeploy_GitMetadata(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
+	t.Setenv("GITHUB_HEAD_REF", "")
+	t.Setenv("GITHUB_REF_TYPE", "")
+	repo := initTestRepo(t, map[string]string{"dist/index.html": "<html></html>"})
+
+	var capturedPath string
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			capturedPath = path
+			return nil
+		},
+	}
+
+	err := runDeploy(client, deployCmdFlags{dir: filepath.Join(repo, "dist"), app: "my-app", gitMetadata: true})
+	require.NoError(t, err)
+	assert.Contains(t, capturedPath, "branch=main")
+	assert.Contains(t, capturedPath, "commit_message=Add+site")
+	assert.Regexp(t, `revision=[0-9a-f]{40}`, capturedPath)
+}
+
+func TestResolveDeployment_SkipsProjectFilesOfInferredDir(t *testing.T) {
+	chdirTestProject(t, map[string]string{
+		"index.html":                "<html></html>",
+		"node_modules/pkg/index.js": "module.exports = {}",
+	})
+
+	d, err := resolveDeployment(deployCmdFlags{app: "my-app"})
+	require.NoError(t, err)
+	assert.True(t, d.bundleOpts.skipProjectFiles)
+
+	d, err = resolveDeployment(deployCmdFlags{app: "my-app", dir: "."})
+	require.NoError(t, err)
+	assert.False(t, d.bundleOpts.skipProjectFiles)
+}
@@ -0,0 +1,544 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/auth"
+	"github.com/cli/go-gh/v2/pkg/text"
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/github/gh-runtime-cli/internal/framework"
+	"github.com/github/gh-runtime-cli/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// cliRepository is where releases of the CLI are published.
+const cliRepository = "github/gh-runtime-cli"
+
+type doctorCmdFlags struct {
+	app    string
+	config string
+	dir    string
+	json   bool
+}
+
+// doctorStatus is the outcome of a doctor check.
+type doctorStatus string
+
+const (
+	doctorPass doctorStatus = "pass"
+	doctorWarn doctorStatus = "warn"
+	doctorFail doctorStatus = "fail"
+)
+
+// doctorCheck is the result of a single doctor check.
+type doctorCheck struct {
+	Name    string       `json:"name"`
+	Status  doctorStatus `json:"status"`
+	Message string       `json:"message"`
+	// Hint suggests how to fix a warning or failure.
+	Hint string `json:"hint,omitempty"`
+}
+
+// doctorReport is everything doctor found, in a form that can be attached to
+// a support ticket.
+type doctorReport struct {
+	Version string        `json:"version"`
+	OS      string        `json:"os"`
+	Host    string        `json:"host"`
+	Checks  []doctorCheck `json:"checks"`
+}
+
+// doctorAuth describes the token gh resolved for the host.
+type doctorAuth struct {
+	host string
+	// source is where the token was read from, such as GH_TOKEN or the gh
+	// config file. It is empty if there is no token.
+	source string
+}
+
+func init() {
+	doctorCmdFlags := doctorCmdFlags{}
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose problems with your environment and configuration",
+		Long: heredoc.Docf(`
+			Checks everything a deploy depends on and reports each check as passed, as a warning, or
+			as failed, with a hint on how to fix it:
+
+			- gh authentication
+			- whether the GitHub API of the host can be reached
+			- whether the runtime config file exists and is valid
+			- whether the app exists and you have permission to deploy to it
+			- whether the deploy directory exists, and the size of the bundle it makes
+			- the state of the git repository
+			- whether a newer version of the CLI is available
+
+			Use %[1]s--json%[1]s to print the report as JSON, for example to attach it to a support ticket.
+			The report does not include your token.
+		`, "`"),
+		Example: heredoc.Doc(`
+			$ gh runtime doctor
+			# => Checks the project in the current directory and its app
+
+			$ gh runtime doctor --app my-app --dir dist
+			# => Checks the app 'my-app' and the directory 'dist'
+
+			$ gh runtime doctor --json > doctor.json
+			# => Saves the report as JSON
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, _ := auth.DefaultHost()
+			token, source := auth.TokenForHost(host)
+
+			// Checks that need the API are skipped when there is no token.
+			var client restClient
+			if token != "" {
+				apiClient, err := newRESTClient()
+				if err != nil {
+					return fmt.Errorf("failed creating REST client: %v", err)
+				}
+				client = apiClient
+			} else {
+				source = ""
+			}
+
+			report := runDoctor(client, doctorAuth{host: host, source: source}, doctorCmdFlags)
+			if doctorCmdFlags.json {
+				err := printJSON(os.Stdout, report)
+				if err != nil {
+					return err
+				}
+			} else {
+				printDoctorReport(os.Stdout, report)
+			}
+
+			if failures := report.count(doctorFail); failures > 0 {
+				return fmt.Errorf("doctor found %s", text.Pluralize(failures, "problem"))
+			}
+			return nil
+		},
+	}
+	doctorCmd.Flags().StringVarP(&doctorCmdFlags.app, "app", "a", "", "The app ID to check")
+	doctorCmd.Flags().StringVarP(&doctorCmdFlags.config, "config", "c", "", "Path to runtime config file")
+	doctorCmd.Flags().StringVarP(&doctorCmdFlags.dir, "dir", "d", "", "The directory to check")
+	doctorCmd.Flags().BoolVar(&doctorCmdFlags.json, "json", false, "Output JSON")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// runDoctor runs every check. client is nil when there is no token, in which
+// case the checks that need the API are left out.
+func runDoctor(client restClient, ghAuth doctorAuth, flags doctorCmdFlags) doctorReport {
+	report := doctorReport{
+		Version: Version,
+		OS:      runtime.GOOS + "/" + runtime.GOARCH,
+		Host:    ghAuth.host,
+		Checks:  []doctorCheck{},
+	}
+
+	if client == nil {
+		report.add(doctorCheck{
+			Name:    "Authentication",
+			Status:  doctorFail,
+			Message: fmt.Sprintf("not logged in to %s", ghAuth.host),
+			Hint:    "run 'gh auth login', or set GH_TOKEN",
+		})
+	} else {
+		report.add(doctorCheck{Name: "Authentication", Status: doctorPass, Message: fmt.Sprintf("token for %s from %s", ghAuth.host, ghAuth.source)})
+		report.add(checkAPI(client, ghAuth.host))
+	}
+
+	runtimeConfig, configCheck := checkRuntimeConfig(flags.config)
+	report.add(configCheck)
+	if client != nil {
+		report.add(checkApp(client, flags.app, runtimeConfig))
+	}
+	report.add(checkDeployDir(flags.dir, runtimeConfig))
+	report.add(checkGit())
+	if client != nil {
+		report.add(checkVersion(client))
+	}
+	return report
+}
+
+func (r *doctorReport) add(check doctorCheck) {
+	r.Checks = append(r.Checks, check)
+}
+
+// count returns the number of checks with the given status.
+func (r doctorReport) count(status doctorStatus) int {
+	n := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// checkAPI checks that the API of the host can be reached with the token, and
+// reports the scopes of classic tokens. Fine-grained and app tokens do not
+// report scopes.
+func checkAPI(client restClient, host string) doctorCheck {
+	check := doctorCheck{Name: "GitHub API"}
+
+	start := time.Now()
+	resp, err := client.Request(http.MethodGet, "user", nil)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		check.Status = doctorFail
+		check.Message, check.Hint = describeCheckError(err)
+		if check.Hint == "" {
+			check.Hint = fmt.Sprintf("check your network connection and proxy settings, and that %s is reachable", host)
+		}
+		return check
+	}
+	defer resp.Body.Close()
+
+	user := struct {
+		Login string `json:"login"`
+	}{}
+	_ = json.NewDecoder(resp.Body).Decode(&user)
+
+	check.Status = doctorPass
+	check.Message = fmt.Sprintf("reached %s in %s", host, elapsed)
+	if user.Login != "" {
+		check.Message += fmt.Sprintf(", logged in as %s", user.Login)
+	}
+	if scopes := splitScopes(resp.Header.Get("X-OAuth-Scopes")); len(scopes) > 0 {
+		check.Message += fmt.Sprintf(" with scopes %s", strings.Join(scopes, ", "))
+	}
+	return check
+}
+
+// checkRuntimeConfig checks that the runtime config file, if there is one, is
+// valid. It returns the configuration for the following checks, which is
+// empty if the file could not be parsed.
+func checkRuntimeConfig(configPath string) (config.RuntimeConfig, doctorCheck) {
+	check := doctorCheck{Name: "Runtime config"}
+
+	path := configPath
+	if path == "" {
+		path = config.DefaultConfigPath
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			check.Status = doctorWarn
+			check.Message = fmt.Sprintf("no %s in the current directory", config.DefaultConfigPath)
+			check.Hint = "run 'gh runtime init --app <app>' to create one, or pass --app and --dir to every command"
+			return config.RuntimeConfig{}, check
+		}
+	}
+
+	runtimeConfig, err := config.ParseRuntimeConfig(path)
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = err.Error()
+		check.Hint = "fix the file, or recreate it with 'gh runtime init'"
+		return config.RuntimeConfig{}, check
+	}
+
+	problems := []string{}
+	if _, err := resolveBundleBudget("", "", runtimeConfig.MaxSize, runtimeConfig.MaxFileSize); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := configRoutes(runtimeConfig); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := newSecretScanner(runtimeConfig.SecretScan); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if len(problems) > 0 {
+		check.Status = doctorFail
+		check.Message = strings.Join(problems, "; ")
+		check.Hint = fmt.Sprintf("fix the settings in '%s'", path)
+		return runtimeConfig, check
+	}
+
+	check.Status = doctorPass
+	check.Message = fmt.Sprintf("'%s' is valid", path)
+	return runtimeConfig, check
+}
+
+// appPermissions are the permissions of the user on an app, as reported by the
+// API along with the app.
+type appPermissions struct {
+	Admin bool `json:"admin"`
+	// Push allows deploying to the app.
+	Push bool `json:"push"`
+	Pull bool `json:"pull"`
+}
+
+// checkApp checks that the app exists and that the user can deploy to it.
+func checkApp(client restClient, appFlag string, runtimeConfig config.RuntimeConfig) doctorCheck {
+	check := doctorCheck{Name: "App"}
+
+	appName := appFlag
+	if appName == "" {
+		appName = runtimeConfig.App
+	}
+	if appName == "" {
+		check.Status = doctorWarn
+		check.Message = "no app is configured"
+		check.Hint = fmt.Sprintf("pass --app, or set 'app' in %s", config.DefaultConfigPath)
+		return check
+	}
+
+	response := struct {
+		serverResponse
+		Permissions *appPermissions `json:"permissions"`
+	}{}
+	err := client.Get(fmt.Sprintf("runtime/%s/deployment", appName), &response)
+	if err != nil {
+		check.Status = doctorFail
+		message, hint := describeCheckError(err)
+		check.Message = fmt.Sprintf("could not retrieve app '%s': %s", appName, message)
+		check.Hint = hint
+		return check
+	}
+
+	location := fmt.Sprintf("app '%s'", appName)
+	if response.AppUrl != "" {
+		location += fmt.Sprintf(" at %s", response.AppUrl)
+	}
+	switch {
+	case response.Permissions == nil:
+		check.Status = doctorWarn
+		check.Message = fmt.Sprintf("%s is accessible, but the API did not report whether you can deploy to it", location)
+	case !response.Permissions.Push && !response.Permissions.Admin:
+		check.Status = doctorFail
+		check.Message = fmt.Sprintf("you can read %s, but not deploy to it", location)
+		check.Hint = fmt.Sprintf("ask an admin of app '%s' for write access", appName)
+	default:
+		check.Status = doctorPass
+		check.Message = fmt.Sprintf("you can deploy to %s", location)
+	}
+	return check
+}
+
+// checkDeployDir checks that the directory to deploy exists and reports the
+// number and size of the files that would be bundled.
+func checkDeployDir(dirFlag string, runtimeConfig config.RuntimeConfig) doctorCheck {
+	check := doctorCheck{Name: "Deploy directory"}
+
+	dir, buildCmd := dirFlag, runtimeConfig.Build
+	if dir == "" {
+		dir = runtimeConfig.Dir
+	}
+	inferred := dir == ""
+	if inferred {
+		detected, err := framework.Detect(".")
+		if err != nil || detected == nil {
+			check.Status = doctorFail
+			check.Message = "no directory is configured and no supported framework was detected"
+			check.Hint = fmt.Sprintf("pass --dir, or set 'dir' in %s", config.DefaultConfigPath)
+			return check
+		}
+		dir = detected.OutputDir
+		if buildCmd == "" {
+			buildCmd = detected.BuildCommand
+		}
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		if buildCmd != "" && errors.Is(err, fs.ErrNotExist) {
+			check.Status = doctorWarn
+			check.Message = fmt.Sprintf("'%s' does not exist yet", dir)
+			check.Hint = fmt.Sprintf("it is created by '%s' when you deploy", buildCmd)
+			return check
+		}
+		check.Status = doctorFail
+		check.Message = fmt.Sprintf("'%s' is not a directory", dir)
+		check.Hint = "pass the directory holding your built app with --dir"
+		return check
+	}
+
+	files, size, err := bundledSize(dir, inferred)
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = fmt.Sprintf("error reading '%s': %v", dir, err)
+		return check
+	}
+
+	check.Status = doctorPass
+	check.Message = fmt.Sprintf("'%s' has %s, %s in total", dir, text.Pluralize(files, "file"), formatByteSize(size))
+	if _, err := os.Stat(filepath.Join(dir, "index.html")); err != nil {
+		check.Status = doctorWarn
+		check.Message += ", but no index.html"
+		check.Hint = "make sure the directory is the output of your build"
+	}
+	return check
+}
+
+// bundledSize returns the number of files a deploy of dir would bundle and
+// their total size, skipping the same entries as the bundle does.
+func bundledSize(dir string, inferred bool) (int, int64, error) {
+	entries, err := collectBundleEntries(dir, bundleOptions{symlinks: symlinksFollow, skipProjectFiles: inferred})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	files, size := 0, int64(0)
+	for _, entry := range entries {
+		if !entry.info.Mode().IsRegular() {
+			continue
+		}
+		files++
+		size += entry.info.Size()
+	}
+	return files, size, nil
+}
+
+// checkGit checks that the current directory is a git repository without
+// uncommitted changes, so that deploys are tagged with their commit.
+func checkGit() doctorCheck {
+	check := doctorCheck{Name: "Git"}
+
+	sha, err := git.HeadSHA(".")
+	if err != nil {
+		check.Status = doctorWarn
+		check.Message = "not in a git repository with commits"
+		check.Hint = "deploys are not tagged with a commit unless you pass --sha"
+		return check
+	}
+
+	location := fmt.Sprintf("commit %s", shortSHA(sha))
+	if branch, _ := git.CurrentBranch("."); branch != "" {
+		location = fmt.Sprintf("branch %s at %s", branch, location)
+	}
+
+	dirty, err := git.IsDirty(".")
+	if err != nil {
+		check.Status = doctorWarn
+		check.Message = fmt.Sprintf("on %s, could not check for uncommitted changes: %v", location, err)
+		return check
+	}
+	if dirty {
+		check.Status = doctorWarn
+		check.Message = fmt.Sprintf("on %s with uncommitted changes", location)
+		check.Hint = "commit your changes, or deploy with --allow-dirty to tag the deploy with the commit anyway"
+		return check
+	}
+
+	check.Status = doctorPass
+	check.Message = fmt.Sprintf("on %s", location)
+	return check
+}
+
+// checkVersion checks whether a newer release of the CLI is available.
+func checkVersion(client restClient) doctorCheck {
+	check := doctorCheck{Name: "CLI version"}
+
+	release := struct {
+		TagName string `json:"tag_name"`
+	}{}
+	err := client.Get(fmt.Sprintf("repos/%s/releases/latest", cliRepository), &release)
+	if err != nil {
+		check.Status = doctorWarn
+		message, _ := describeCheckError(err)
+		check.Message = fmt.Sprintf("%s, could not check for a newer version: %s", Version, message)
+		return check
+	}
+
+	if _, ok := parseVersion(Version); !ok {
+		check.Status = doctorWarn
+		check.Message = fmt.Sprintf("%s is not a release build, the latest release is %s", Version, strings.TrimPrefix(release.TagName, "v"))
+		check.Hint = "run 'gh extension upgrade runtime' to install the latest release"
+		return check
+	}
+	if _, ok := parseVersion(release.TagName); !ok {
+		check.Status = doctorWarn
+		check.Message = fmt.Sprintf("%s, could not compare it with the latest release '%s'", Version, release.TagName)
+		return check
+	}
+
+	if newerVersion(release.TagName, Version) {
+		check.Status = doctorWarn
+		check.Message = fmt.Sprintf("%s, but %s is available", Version, strings.TrimPrefix(release.TagName, "v"))
+		check.Hint = "run 'gh extension upgrade runtime'"
+		return check
+	}
+
+	check.Status = doctorPass
+	check.Message = fmt.Sprintf("%s is the latest version", Version)
+	return check
+}
+
+// newerVersion reports whether latest is a later version than current. It is
+// false if either is not a version, see parseVersion.
+func newerVersion(latest, current string) bool {
+	latestParts, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	currentParts, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := 0; i < max(len(latestParts), len(currentParts)); i++ {
+		var l, c int
+		if i < len(latestParts) {
+			l = latestParts[i]
+		}
+		if i < len(currentParts) {
+			c = currentParts[i]
+		}
+		if l != c {
+			return l > c
+		}
+	}
+	return false
+}
+
+// parseVersion parses a version of dot-separated numbers with an optional "v"
+// prefix, such as "v0.0.11". Development builds such as "dev" are not
+// versions.
+func parseVersion(version string) ([]int, bool) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		numbers[i] = n
+	}
+	return numbers, true
+}
+
+// describeCheckError returns a one line description of err and the hint for
+// it, if it is a common API error.
+func describeCheckError(err error) (string, string) {
+	var e *apiError
+	if errors.As(describeAPIError(err), &e) {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message), e.Hint
+	}
+	return err.Error(), ""
+}
+
+func printDoctorReport(w io.Writer, report doctorReport) {
+	symbols := map[doctorStatus]string{doctorPass: "✓", doctorWarn: "!", doctorFail: "X"}
+	for _, check := range report.Checks {
+		fmt.Fprintf(w, "%s %s: %s\n", symbols[check.Status], check.Name, check.Message)
+		if check.Hint != "" {
+			fmt.Fprintf(w, "  %s\n", check.Hint)
+		}
+	}
+
+	warnings, failures := report.count(doctorWarn), report.count(doctorFail)
+	if warnings == 0 && failures == 0 {
+		fmt.Fprintln(w, "\nNo problems found")
+		return
+	}
+	fmt.Fprintf(w, "\n%s, %s\n", text.Pluralize(failures, "problem"), text.Pluralize(warnings, "warning"))
+}
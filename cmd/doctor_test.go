@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDoctorClient returns a client for a logged in user with the given
+// token scopes, who can deploy to app 'my-app', where the latest release is
+// latest.
+func newDoctorClient(scopes, latest string) *mockRESTClient {
+	return &mockRESTClient{
+		requestFunc: func(method string, path string, body io.Reader) (*http.Response, error) {
+			header := http.Header{}
+			if scopes != "" {
+				header.Set("X-OAuth-Scopes", scopes)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(`{"login":"monalisa"}`)),
+			}, nil
+		},
+		getFunc: func(path string, resp interface{}) error {
+			switch path {
+			case "runtime/my-app/deployment":
+				return mockGetResponse(`{"app_url":"https://my-app.example.com","permissions":{"admin":false,"push":true,"pull":true}}`)(path, resp)
+			case "repos/github/gh-runtime-cli/releases/latest":
+				return mockGetResponse(`{"tag_name":"`+latest+`"}`)(path, resp)
+			}
+			return &api.HTTPError{StatusCode: http.StatusNotFound, Message: "Not Found", RequestURL: &url.URL{Path: "/" + path}, Headers: http.Header{}}
+		},
+	}
+}
+
+// chdirTestProject changes to a temporary directory holding files for the
+// duration of the test.
+func chdirTestProject(t *testing.T, files map[string]string) {
+	tmp := t.TempDir()
+	writeTestTree(t, tmp, files)
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	t.Cleanup(func() { os.Chdir(origDir) })
+}
+
+func doctorChecks(report doctorReport) map[string]doctorCheck {
+	checks := map[string]doctorCheck{}
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+	return checks
+}
+
+func TestRunDoctor_Healthy(t *testing.T) {
+	chdirTestProject(t, map[string]string{
+		"runtime.config.json": `{"app": "my-app", "dir": "dist"}`,
+		"dist/index.html":     "<html></html>",
+		"dist/app.js":         "console.log('hi')",
+	})
+
+	report := runDoctor(newDoctorClient("repo, read:org", "v"+Version), doctorAuth{host: "github.com", source: "GH_TOKEN"}, doctorCmdFlags{})
+	checks := doctorChecks(report)
+
+	assert.Equal(t, doctorPass, checks["Authentication"].Status)
+	assert.Equal(t, "token for github.com from GH_TOKEN", checks["Authentication"].Message)
+	assert.Equal(t, doctorPass, checks["GitHub API"].Status)
+	assert.Contains(t, checks["GitHub API"].Message, "logged in as monalisa with scopes repo, read:org")
+	assert.Equal(t, doctorPass, checks["Runtime config"].Status)
+	assert.Equal(t, doctorCheck{Name: "App", Status: doctorPass, Message: "you can deploy to app 'my-app' at https://my-app.example.com"}, checks["App"])
+	assert.Equal(t, doctorCheck{Name: "Deploy directory", Status: doctorPass, Message: "'dist' has 2 files, 30 B in total"}, checks["Deploy directory"])
+	assert.Equal(t, doctorPass, checks["CLI version"].Status)
+	assert.Equal(t, 0, report.count(doctorFail))
+}
+
+func TestRunDoctor_NotLoggedIn(t *testing.T) {
+	chdirTestProject(t, map[string]string{
+		"runtime.config.json": `{"app": "my-app", "dir": "dist"}`,
+		"dist/index.html":     "<html></html>",
+	})
+
+	report := runDoctor(nil, doctorAuth{host: "github.com"}, doctorCmdFlags{})
+	checks := doctorChecks(report)
+
+	assert.Equal(t, doctorCheck{Name: "Authentication", Status: doctorFail, Message: "not logged in to github.com", Hint: "run 'gh auth login', or set GH_TOKEN"}, checks["Authentication"])
+	assert.NotContains(t, checks, "GitHub API")
+	assert.NotContains(t, checks, "App")
+	assert.NotContains(t, checks, "CLI version")
+	assert.Contains(t, checks, "Deploy directory")
+}
+
+func TestRunDoctor_Problems(t *testing.T) {
+	chdirTestProject(t, map[string]string{
+		"runtime.config.json": `{"app": "other-app", "dir": "build", "build": "npm run build", "maxSize": "lots"}`,
+	})
+
+	report := runDoctor(newDoctorClient("read:org", "v99.0.0"), doctorAuth{host: "github.com", source: "oauth_token"}, doctorCmdFlags{})
+	checks := doctorChecks(report)
+
+	assert.Equal(t, doctorFail, checks["Runtime config"].Status)
+	assert.Contains(t, checks["Runtime config"].Message, "lots")
+
+	// The app is still checked when the config file has invalid settings, as
+	// long as it can be parsed.
+	assert.Equal(t, doctorFail, checks["App"].Status)
+	assert.Equal(t, "could not retrieve app 'other-app': HTTP 404: Not Found", checks["App"].Message)
+	assert.Equal(t, "check that app 'other-app' exists and that you have access to it", checks["App"].Hint)
+
+	assert.Equal(t, doctorCheck{Name: "Deploy directory", Status: doctorWarn, Message: "'build' does not exist yet", Hint: "it is created by 'npm run build' when you deploy"}, checks["Deploy directory"])
+
+	assert.Equal(t, doctorWarn, checks["CLI version"].Status)
+	assert.Equal(t, "run 'gh extension upgrade runtime'", checks["CLI version"].Hint)
+}
+
+func TestRunDoctor_NoConfig(t *testing.T) {
+	chdirTestProject(t, map[string]string{"site/index.html": "<html></html>"})
+
+	report := runDoctor(newDoctorClient("", "v"+Version), doctorAuth{host: "github.com", source: "GH_TOKEN"}, doctorCmdFlags{dir: "site"})
+	checks := doctorChecks(report)
+
+	assert.Equal(t, doctorWarn, checks["Runtime config"].Status)
+	assert.NotContains(t, checks["GitHub API"].Message, "scopes")
+	assert.Equal(t, doctorWarn, checks["App"].Status)
+	assert.Equal(t, doctorPass, checks["Deploy directory"].Status)
+}
+
+func TestCheckApp_Permissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected doctorCheck
+	}{
+		{
+			name:     "admin",
+			response: `{"app_url":"https://my-app.example.com","permissions":{"admin":true}}`,
+			expected: doctorCheck{Name: "App", Status: doctorPass, Message: "you can deploy to app 'my-app' at https://my-app.example.com"},
+		},
+		{
+			name:     "read only",
+			response: `{"app_url":"https://my-app.example.com","permissions":{"admin":false,"push":false,"pull":true}}`,
+			expected: doctorCheck{Name: "App", Status: doctorFail, Message: "you can read app 'my-app' at https://my-app.example.com, but not deploy to it", Hint: "ask an admin of app 'my-app' for write access"},
+		},
+		{
+			name:     "not reported",
+			response: `{"app_url":"https://my-app.example.com"}`,
+			expected: doctorCheck{Name: "App", Status: doctorWarn, Message: "app 'my-app' at https://my-app.example.com is accessible, but the API did not report whether you can deploy to it"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockRESTClient{getFunc: mockGetResponse(tt.response)}
+			assert.Equal(t, tt.expected, checkApp(client, "my-app", config.RuntimeConfig{}))
+		})
+	}
+}
+
+func TestCheckDeployDir_SkipsProjectFilesOfInferredDir(t *testing.T) {
+	chdirTestProject(t, map[string]string{
+		"index.html":                "<html></html>",
+		"node_modules/pkg/index.js": "module.exports = {}",
+		".git/HEAD":                 "ref: refs/heads/main",
+	})
+
+	check := checkDeployDir("", config.RuntimeConfig{})
+	assert.Equal(t, "'.' has 1 file, 13 B in total", check.Message)
+
+	check = checkDeployDir(".", config.RuntimeConfig{})
+	assert.Equal(t, "'.' has 3 files, 52 B in total", check.Message)
+}
+
+func TestPrintDoctorReport(t *testing.T) {
+	var out strings.Builder
+	printDoctorReport(&out, doctorReport{Checks: []doctorCheck{
+		{Name: "Authentication", Status: doctorPass, Message: "token for github.com from GH_TOKEN"},
+		{Name: "Git", Status: doctorWarn, Message: "on commit abc1234 with uncommitted changes", Hint: "commit your changes"},
+		{Name: "App", Status: doctorFail, Message: "could not retrieve app 'my-app': HTTP 404: Not Found"},
+	}})
+
+	assert.Equal(t, `✓ Authentication: token for github.com from GH_TOKEN
+! Git: on commit abc1234 with uncommitted changes
+  commit your changes
+X App: could not retrieve app 'my-app': HTTP 404: Not Found
+
+1 problem, 1 warning
+`, out.String())
+}
+
+func TestNewerVersion(t *testing.T) {
+	tests := []struct {
+		latest   string
+		current  string
+		expected bool
+	}{
+		{latest: "v0.0.12", current: "0.0.11", expected: true},
+		{latest: "v0.1.0", current: "0.0.11", expected: true},
+		{latest: "v0.0.11", current: "0.0.11", expected: false},
+		{latest: "v0.0.10", current: "0.0.11", expected: false},
+		{latest: "v1.0", current: "0.9.9", expected: true},
+		{latest: "nightly", current: "0.0.11", expected: false},
+		{latest: "v0.0.12", current: "dev", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.latest, func(t *testing.T) {
+			assert.Equal(t, tt.expected, newerVersion(tt.latest, tt.current))
+		})
+	}
+}
+
+func TestCheckVersion_NotARelease(t *testing.T) {
+	version := Version
+	Version = "dev"
+	defer func() { Version = version }()
+
+	check := checkVersion(newDoctorClient("", "v0.0.11"))
+	assert.Equal(t, doctorCheck{
+		Name:    "CLI version",
+		Status:  doctorWarn,
+		Message: "dev is not a release build, the latest release is 0.0.11",
+		Hint:    "run 'gh extension upgrade runtime' to install the latest release",
+	}, check)
+
+	Version = version
+	check = checkVersion(newDoctorClient("", "nightly"))
+	assert.Equal(t, doctorWarn, check.Status)
+	assert.Equal(t, Version+", could not compare it with the latest release 'nightly'", check.Message)
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// responseError is the api.HTTPError of an error response along with the
+// documentation_url of its body, which api.HTTPError does not keep. It is
+// returned by errorDetailsTransport and unwraps to the api.HTTPError.
+type responseError struct {
+	DocumentationURL string
+
+	err *api.HTTPError
+}
+
+func (e *responseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *responseError) Unwrap() error {
+	return e.err
+}
+
+// apiError is an error response from the GitHub API, unpacked from the
+// api.HTTPError returned by the REST client. It unwraps to the api.HTTPError.
+type apiError struct {
+	StatusCode int
+	// Message is the message of the response, or the status text if it has none.
+	Message string
+	// Details are the messages of the individual errors of the response.
+	Details          []string
+	DocumentationURL string
+	RequestID        string
+	// Hint suggests how to fix common errors.
+	Hint string
+
+	err *api.HTTPError
+}
+
+func (e *apiError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "HTTP %d: %s", e.StatusCode, e.Message)
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request ID %s)", e.RequestID)
+	}
+	for _, detail := range e.Details {
+		fmt.Fprintf(&b, "\n  - %s", detail)
+	}
+	if e.DocumentationURL != "" {
+		fmt.Fprintf(&b, "\nSee %s", e.DocumentationURL)
+	}
+	if e.Hint != "" {
+		fmt.Fprintf(&b, "\nHint: %s", e.Hint)
+	}
+	return b.String()
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+// describeAPIError turns an error response from the GitHub API into an
+// *apiError. Other errors are returned unchanged.
+func describeAPIError(err error) error {
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) {
+		return err
+	}
+
+	e := &apiError{
+		StatusCode: httpErr.StatusCode,
+		RequestID:  httpErr.Headers.Get("X-GitHub-Request-Id"),
+		err:        httpErr,
+	}
+	var respErr *responseError
+	if errors.As(err, &respErr) {
+		e.DocumentationURL = respErr.DocumentationURL
+	}
+
+	// go-gh joins the message of the response and those of its errors with
+	// line breaks, and uses the status line when the response is not JSON.
+	lines := strings.Split(httpErr.Message, "\n")
+	e.Message, e.Details = lines[0], lines[1:]
+	if e.Message == "" || e.Message == fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)) {
+		e.Message = http.StatusText(e.StatusCode)
+	}
+	e.Hint = apiErrorHint(httpErr, e)
+	return e
+}
+
+// apiErrorHint suggests a fix for common error responses.
+func apiErrorHint(httpErr *api.HTTPError, e *apiError) string {
+	appName, revisionName := requestedApp(httpErr)
+
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return "your GitHub token is missing, expired or revoked; run 'gh auth login' to sign in again"
+	case http.StatusForbidden:
+		if missing := missingScopes(httpErr.Headers); len(missing) > 0 {
+			return fmt.Sprintf("your GitHub token is missing the %s scope; run 'gh auth refresh --scopes %s'", strings.Join(missing, ", "), strings.Join(missing, ","))
+		}
+		if appName != "" {
+			return fmt.Sprintf("check that you have access to app '%s'", appName)
+		}
+	case http.StatusNotFound:
+		if appName == "" {
+			return ""
+		}
+		if revisionName != "" {
+			return fmt.Sprintf("check that revision '%s' exists with 'gh runtime revision list --app %s'", revisionName, appName)
+		}
+		return fmt.Sprintf("check that app '%s' exists and that you have access to it", appName)
+	case http.StatusConflict, http.StatusUnprocessableEntity:
+		if nameTaken(httpErr, e) {
+			return "the app name is already taken; choose another --name, or use --app to deploy to the existing app"
+		}
+	case http.StatusRequestEntityTooLarge:
+		return "the bundle is too large; see what takes up space with 'gh runtime deploy --dry-run' and set --max-size to catch this before uploading"
+	}
+	return ""
+}
+
+// requestedApp returns the app and revision named by the URL of a runtime API
+// request, if any.
+func requestedApp(httpErr *api.HTTPError) (string, string) {
+	if httpErr.RequestURL == nil {
+		return "", ""
+	}
+
+	elements := strings.Split(strings.Trim(httpErr.RequestURL.Path, "/"), "/")
+	index := slices.Index(elements, "runtime")
+	if index < 0 || index+1 >= len(elements) {
+		return "", ""
+	}
+	return elements[index+1], httpErr.RequestURL.Query().Get("revision_name")
+}
+
+// missingScopes returns the OAuth scopes accepted by the endpoint that the
+// token does not have, when the endpoint reports them.
+func missingScopes(headers http.Header) []string {
+	accepted := splitScopes(headers.Get("X-Accepted-OAuth-Scopes"))
+	if len(accepted) == 0 || headers.Values("X-OAuth-Scopes") == nil {
+		return nil
+	}
+
+	granted := splitScopes(headers.Get("X-OAuth-Scopes"))
+	for _, scope := range accepted {
+		if slices.Contains(granted, scope) {
+			return nil
+		}
+	}
+	return accepted
+}
+
+func splitScopes(value string) []string {
+	scopes := []string{}
+	for _, scope := range strings.Split(value, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// nameTaken reports whether an error response says that an app name is
+// already in use.
+func nameTaken(httpErr *api.HTTPError, e *apiError) bool {
+	for _, item := range httpErr.Errors {
+		if item.Code == "already_exists" {
+			return true
+		}
+	}
+	for _, message := range append([]string{e.Message}, e.Details...) {
+		message = strings.ToLower(message)
+		if strings.Contains(message, "already exists") || strings.Contains(message, "already taken") || strings.Contains(message, "already been taken") {
+			return true
+		}
+	}
+	return false
+}
+
+// errorDetailsTransport turns error responses into a *responseError, so that
+// describeAPIError can report the documentation_url of their body.
+type errorDetailsTransport struct {
+	base http.RoundTripper
+}
+
+func (t *errorDetailsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode < http.StatusBadRequest {
+		return resp, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var httpErr *api.HTTPError
+	if !errors.As(api.HandleHTTPError(resp), &httpErr) {
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	e := &responseError{err: httpErr}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		var payload struct {
+			DocumentationURL string `json:"documentation_url"`
+		}
+		if json.Unmarshal(body, &payload) == nil {
+			e.DocumentationURL = payload.DocumentationURL
+		}
+	}
+	return nil, e
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeAPIError(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		status      int
+		headers     map[string]string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{
+			name:        "unauthorized",
+			path:        "/runtime/my-app/deployment",
+			status:      http.StatusUnauthorized,
+			contentType: "application/json",
+			body:        `{"message":"Bad credentials","documentation_url":"https://docs.github.com/rest"}`,
+			expected: "HTTP 401: Bad credentials (request ID ABCD:1234)\n" +
+				"See https://docs.github.com/rest\n" +
+				"Hint: your GitHub token is missing, expired or revoked; run 'gh auth login' to sign in again",
+		},
+		{
+			name:        "missing scope",
+			path:        "/runtime/my-app/deployment",
+			status:      http.StatusForbidden,
+			headers:     map[string]string{"X-Accepted-OAuth-Scopes": "runtime", "X-OAuth-Scopes": "repo, read:org"},
+			contentType: "application/json",
+			body:        `{"message":"Resource not accessible by integration"}`,
+			expected: "HTTP 403: Resource not accessible by integration (request ID ABCD:1234)\n" +
+				"Hint: your GitHub token is missing the runtime scope; run 'gh auth refresh --scopes runtime'",
+		},
+		{
+			name:        "app not found",
+			path:        "/runtime/my-app/deployment",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"message":"Not Found"}`,
+			expected: "HTTP 404: Not Found (request ID ABCD:1234)\n" +
+				"Hint: check that app 'my-app' exists and that you have access to it",
+		},
+		{
+			name:        "revision not found",
+			path:        "/runtime/my-app/deployment?revision_name=v2",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"message":"Not Found"}`,
+			expected: "HTTP 404: Not Found (request ID ABCD:1234)\n" +
+				"Hint: check that revision 'v2' exists with 'gh runtime revision list --app my-app'",
+		},
+		{
+			name:        "name taken",
+			path:        "/runtime",
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+			body:        `{"message":"Validation Failed","errors":[{"resource":"App","field":"name","code":"already_exists"}]}`,
+			expected: "HTTP 422: Validation Failed (request ID ABCD:1234)\n" +
+				"  - App.name already exists\n" +
+				"Hint: the app name is already taken; choose another --name, or use --app to deploy to the existing app",
+		},
+		{
+			name:        "bundle too large",
+			path:        "/runtime/my-app/deployment/bundle",
+			status:      http.StatusRequestEntityTooLarge,
+			contentType: "text/html",
+			body:        "<html>Request Entity Too Large</html>",
+			expected: "HTTP 413: Request Entity Too Large (request ID ABCD:1234)\n" +
+				"Hint: the bundle is too large; see what takes up space with 'gh runtime deploy --dry-run' and set --max-size to catch this before uploading",
+		},
+		{
+			name:        "server error",
+			path:        "/runtime/my-app/deployment",
+			status:      http.StatusInternalServerError,
+			contentType: "application/json",
+			body:        `{"message":"Server Error"}`,
+			expected:    "HTTP 500: Server Error (request ID ABCD:1234)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Header().Set("X-GitHub-Request-Id", "ABCD:1234")
+				for name, value := range tt.headers {
+					w.Header().Set(name, value)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			client, err := api.NewRESTClient(api.ClientOptions{
+				Host:      "github.com",
+				AuthToken: "token",
+				Transport: &errorDetailsTransport{base: http.DefaultTransport},
+			})
+			require.NoError(t, err)
+
+			err = client.Get(server.URL+tt.path, nil)
+			err = fmt.Errorf("error deploying app: %w", describeAPIError(err))
+			assert.EqualError(t, err, "error deploying app: "+tt.expected)
+
+			var described *apiError
+			require.True(t, errors.As(err, &described))
+			assert.Equal(t, tt.status, described.StatusCode)
+			var httpErr *api.HTTPError
+			require.True(t, errors.As(err, &httpErr))
+			assert.Equal(t, tt.status, httpErr.StatusCode)
+		})
+	}
+}
+
+func TestDescribeAPIError_OtherErrors(t *testing.T) {
+	err := errors.New("connection refused")
+	assert.Equal(t, err, describeAPIError(err))
+}
@@ -1,11 +1,12 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
 
 	"github.com/MakeNowJust/heredoc"
-	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/github/gh-runtime-cli/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -14,10 +15,13 @@ type getCmdFlags struct {
 	app          string
 	revisionName string
 	config       string
+	sbom         bool
 }
 
 type serverResponse struct {
 	AppUrl string `json:"app_url"`
+	// HtmlUrl is the page to manage the app on GitHub.
+	HtmlUrl string `json:"html_url,omitempty"`
 }
 
 func init() {
@@ -39,13 +43,24 @@ func init() {
 			
 			$ gh runtime get
 			# => Retrieves details using app ID from runtime.config.json in current directory (if it exists).
+
+			$ gh runtime get --app my-app --sbom > sbom.spdx.json
+			# => Saves the SPDX SBOM uploaded with 'gh runtime deploy --sbom' for the live revision.
 		`),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := api.DefaultRESTClient()
+			client, err := newRESTClient()
 			if err != nil {
 				return fmt.Errorf("failed creating REST client: %v", err)
 			}
 
+			if getCmdFlags.sbom {
+				bom, err := runGetSBOM(client, getCmdFlags)
+				if err != nil {
+					return err
+				}
+				return printJSON(os.Stdout, bom)
+			}
+
 			appUrl, err := runGet(client, getCmdFlags)
 			if err != nil {
 				return err
@@ -59,6 +74,7 @@ func init() {
 	getCmd.Flags().StringVarP(&getCmdFlags.app, "app", "a", "", "The app ID to retrieve details for")
 	getCmd.Flags().StringVarP(&getCmdFlags.config, "config", "c", "", "Path to runtime config file")
 	getCmd.Flags().StringVarP(&getCmdFlags.revisionName, "revision-name", "r", "", "The revision name to use for the app")
+	getCmd.Flags().BoolVar(&getCmdFlags.sbom, "sbom", false, "Print the SPDX SBOM of the revision instead of its URL")
 	rootCmd.AddCommand(getCmd)
 }
 
@@ -68,20 +84,53 @@ func runGet(client restClient, flags getCmdFlags) (string, error) {
 		return "", err
 	}
 
-	getUrl := fmt.Sprintf("runtime/%s/deployment", appName)
-	params := url.Values{}
-	if flags.revisionName != "" {
-		params.Add("revision_name", flags.revisionName)
+	response := serverResponse{}
+	err = client.Get(deploymentURL(appName, "", revisionQuery(flags.revisionName, "")), &response)
+	if err != nil {
+		return "", fmt.Errorf("retrieving app details: %w", describeAPIError(err))
 	}
-	if len(params) > 0 {
-		getUrl += "?" + params.Encode()
+
+	return response.AppUrl, nil
+}
+
+// runGetSBOM returns the SBOM that was uploaded with the revision.
+func runGetSBOM(client restClient, flags getCmdFlags) (json.RawMessage, error) {
+	appName, err := config.ResolveAppName(flags.app, flags.config)
+	if err != nil {
+		return nil, err
 	}
 
-	response := serverResponse{}
-	err = client.Get(getUrl, &response)
+	var response json.RawMessage
+	err = client.Get(deploymentURL(appName, "sbom", revisionQuery(flags.revisionName, "")), &response)
 	if err != nil {
-		return "", fmt.Errorf("retrieving app details: %v", err)
+		return nil, fmt.Errorf("retrieving SBOM: %w", describeAPIError(err))
 	}
+	return response, nil
+}
 
-	return response.AppUrl, nil
+// deploymentURL returns the URL of the deployment of an app, or of one of its
+// endpoints such as "sbom", with query added. Every request to the deployment
+// API builds its URL here, so that they name and encode parameters alike.
+func deploymentURL(appName, endpoint string, query url.Values) string {
+	deploymentUrl := fmt.Sprintf("runtime/%s/deployment", appName)
+	if endpoint != "" {
+		deploymentUrl += "/" + endpoint
+	}
+	if len(query) > 0 {
+		deploymentUrl += "?" + query.Encode()
+	}
+	return deploymentUrl
+}
+
+// revisionQuery returns the query parameters that scope a deployment request
+// to a revision, by its name and its SHA, when they are set.
+func revisionQuery(revisionName, sha string) url.Values {
+	query := url.Values{}
+	if revisionName != "" {
+		query.Add("revision_name", revisionName)
+	}
+	if sha != "" {
+		query.Add("revision", sha)
+	}
+	return query
 }
@@ -7,8 +7,8 @@ import (
 	"path/filepath"
 
 	"github.com/MakeNowJust/heredoc"
-	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/github/gh-runtime-cli/internal/framework"
 	"github.com/spf13/cobra"
 )
 
@@ -31,6 +31,8 @@ func init() {
 			This creates a runtime.config.json configuration file that binds your local project
 			to a remote Spark app. You must specify an app ID to validate the app exists.
 			Optionally specify an output path where the runtime.config.json file should be created.
+			If a supported framework is detected in the current directory, its output directory and
+			build command are also written to the configuration file.
 		`),
 		Example: heredoc.Doc(`
 			$ gh runtime init --app my-spark-app
@@ -43,7 +45,7 @@ func init() {
 			# => Creates configuration with a custom filename
 		`),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := api.DefaultRESTClient()
+			client, err := newRESTClient()
 			if err != nil {
 				return fmt.Errorf("failed creating REST client: %v", err)
 			}
@@ -67,19 +69,28 @@ func runInit(client restClient, flags initCmdFlags) error {
 	response := appResponse{}
 	err := client.Get(getUrl, &response)
 	if err != nil {
-		return fmt.Errorf("app '%s' does not exist or is not accessible: %v", flags.app, err)
+		return fmt.Errorf("app '%s' does not exist or is not accessible: %w", flags.app, describeAPIError(err))
 	}
 
-	return writeRuntimeConfig(flags.app, flags.out)
-}
-
-// writeRuntimeConfig writes a runtime.config.json file for the given app.
-// If outPath is empty, it defaults to "runtime.config.json" in the current directory.
-func writeRuntimeConfig(app string, outPath string) error {
 	configStruct := config.RuntimeConfig{
-		App: app,
+		App: flags.app,
 	}
 
+	detected, err := framework.Detect(".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: error detecting project framework: %v\n", err)
+	} else if detected != nil {
+		printDetectedFramework(detected, detected.BuildCommand)
+		configStruct.Dir = detected.OutputDir
+		configStruct.Build = detected.BuildCommand
+	}
+
+	return writeRuntimeConfig(configStruct, flags.out)
+}
+
+// writeRuntimeConfig writes a runtime.config.json file with the given configuration.
+// If outPath is empty, it defaults to "runtime.config.json" in the current directory.
+func writeRuntimeConfig(configStruct config.RuntimeConfig, outPath string) error {
 	configPath := "runtime.config.json"
 	if outPath != "" {
 		configPath = outPath
@@ -102,6 +113,6 @@ func writeRuntimeConfig(app string, outPath string) error {
 		return fmt.Errorf("error writing configuration file: %v", err)
 	}
 
-	fmt.Printf("Successfully initialized local project for Spark app '%s' at '%s'\n", app, configPath)
+	fmt.Printf("Successfully initialized local project for Spark app '%s' at '%s'\n", configStruct.App, configPath)
 	return nil
 }
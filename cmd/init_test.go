@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/github/gh-runtime-cli/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -84,3 +85,27 @@ func TestRunInit_VerifiesAPIBeforeWriting(t *testing.T) {
 	require.Error(t, err)
 	require.NoFileExists(t, "runtime.config.json")
 }
+
+func TestRunInit_WritesDetectedFramework(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	require.NoError(t, os.WriteFile("package.json", []byte(`{"scripts":{"build":"react-scripts build"},"dependencies":{"react-scripts":"5.0.1"}}`), 0644))
+
+	client := &mockRESTClient{
+		getFunc: mockGetResponse(`{"app_url":"https://my-app.example.com"}`),
+	}
+
+	err = runInit(client, initCmdFlags{app: "my-app"})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile("runtime.config.json")
+	require.NoError(t, err)
+
+	var cfg config.RuntimeConfig
+	require.NoError(t, json.Unmarshal(data, &cfg))
+	assert.Equal(t, config.RuntimeConfig{App: "my-app", Dir: "build", Build: "npm run build"}, cfg)
+}
@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// logsPollInterval is how often new log entries are fetched with --follow.
+var logsPollInterval = 2 * time.Second
+
+// logsMaxBackoff is the longest wait between attempts to reconnect after
+// fetching logs failed with --follow.
+var logsMaxBackoff = 30 * time.Second
+
+// logLevels are the levels of log entries, from least to most severe.
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// logLevelColors are the ANSI colors of each level in human output.
+var logLevelColors = map[string]string{
+	"debug": "\x1b[90m",
+	"info":  "\x1b[36m",
+	"warn":  "\x1b[33m",
+	"error": "\x1b[31m",
+}
+
+const (
+	ansiDim   = "\x1b[2m"
+	ansiReset = "\x1b[0m"
+)
+
+type logsCmdFlags struct {
+	app          string
+	config       string
+	revisionName string
+	since        string
+	level        string
+	grep         string
+	regex        bool
+	follow       bool
+	json         bool
+}
+
+// logEntry is a line logged by a deployed app.
+type logEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Message   string    `json:"message"`
+	// Revision is the name of the revision that logged the entry.
+	Revision string `json:"revision,omitempty"`
+}
+
+// logsPage is a batch of log entries returned by the API.
+type logsPage struct {
+	Entries []logEntry `json:"entries"`
+	// Cursor is passed back to fetch the entries after this page.
+	Cursor string `json:"cursor"`
+	// HasMore is set when more entries are available right away.
+	HasMore bool `json:"has_more"`
+}
+
+func init() {
+	logsCmdFlags := logsCmdFlags{}
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the logs of a GitHub Runtime app",
+		Long: heredoc.Docf(`
+			Show the logs of a GitHub Runtime app, oldest first.
+			The app is resolved the same way as by 'gh runtime get': from the --app flag, the --config flag,
+			or runtime.config.json in the current directory.
+
+			With --follow, new entries are printed as they are logged until interrupted, and fetching is
+			retried with a growing delay if the connection drops.
+
+			Entries can be filtered by their minimum level with --level, and by their message with --grep,
+			which matches text, or a regular expression with --regex. With --json, every entry is printed as
+			a line of JSON with the fields %[1]stimestamp%[1]s, %[1]slevel%[1]s, %[1]smessage%[1]s and %[1]srevision%[1]s.
+		`, "`"),
+		Example: heredoc.Doc(`
+			$ gh runtime logs --since 1h
+			# => Shows the logs of the last hour of the app from runtime.config.json
+
+			$ gh runtime logs --app my-app --follow --level error
+			# => Tails the errors logged by the app with ID 'my-app'
+
+			$ gh runtime logs --revision-name v2 --grep 'timeout|refused' --regex
+			# => Shows the entries of revision 'v2' matching a regular expression
+
+			$ gh runtime logs --since 2024-01-02T15:04:05Z --json | jq .message
+			# => Prints the messages logged since a point in time
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newRESTClient()
+			if err != nil {
+				return fmt.Errorf("failed creating REST client: %v", err)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			printer := logPrinter{w: os.Stdout, json: logsCmdFlags.json, color: term.FromEnv().IsColorEnabled()}
+			return runLogs(ctx, client, logsCmdFlags, printer, os.Stderr)
+		},
+	}
+
+	logsCmd.Flags().StringVarP(&logsCmdFlags.app, "app", "a", "", "The app ID to show the logs of")
+	logsCmd.Flags().StringVarP(&logsCmdFlags.config, "config", "c", "", "Path to runtime config file")
+	logsCmd.Flags().StringVarP(&logsCmdFlags.revisionName, "revision-name", "r", "", "Only show the logs of this revision")
+	logsCmd.Flags().StringVar(&logsCmdFlags.since, "since", "", "Show entries logged since a duration ago, such as '1h' or '2d', or since a timestamp")
+	logsCmd.Flags().StringVar(&logsCmdFlags.level, "level", "", "Only show entries at this level or above: 'debug', 'info', 'warn' or 'error'")
+	logsCmd.Flags().StringVar(&logsCmdFlags.grep, "grep", "", "Only show entries whose message contains this text")
+	logsCmd.Flags().BoolVar(&logsCmdFlags.regex, "regex", false, "Treat --grep as a regular expression")
+	logsCmd.Flags().BoolVarP(&logsCmdFlags.follow, "follow", "f", false, "Keep printing new entries as they are logged")
+	logsCmd.Flags().BoolVar(&logsCmdFlags.json, "json", false, "Output each entry as a line of JSON")
+	rootCmd.AddCommand(logsCmd)
+}
+
+// runLogs prints the logs of an app. With --follow it polls for new entries
+// until ctx is done.
+func runLogs(ctx context.Context, client restClient, flags logsCmdFlags, printer logPrinter, warnings io.Writer) error {
+	appName, err := config.ResolveAppName(flags.app, flags.config)
+	if err != nil {
+		return err
+	}
+
+	filter, err := newLogFilter(flags.level, flags.grep, flags.regex)
+	if err != nil {
+		return err
+	}
+
+	var since time.Time
+	if flags.since != "" {
+		since, err = parseSince(flags.since, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	cursor := ""
+	backoff := time.Duration(0)
+	stalled := false
+	// Pages are fetched again when the cursor is missing or does not
+	// advance, so entries that were already printed are skipped.
+	printed := logDeduper{}
+	for {
+		page, err := fetchLogs(client, appName, flags.revisionName, since, cursor)
+		if err != nil {
+			if !flags.follow || !retryableLogsError(err) {
+				return err
+			}
+			backoff = min(max(2*backoff, time.Second), logsMaxBackoff)
+			fmt.Fprintf(warnings, "warning: %v, retrying in %s\n", err, backoff)
+			if !sleepContext(ctx, backoff) {
+				return nil
+			}
+			continue
+		}
+		backoff = 0
+
+		for _, entry := range page.Entries {
+			if printed.seen(entry) || !filter.match(entry) {
+				continue
+			}
+			err = printer.print(entry)
+			if err != nil {
+				return err
+			}
+		}
+		advanced := page.Cursor != "" && page.Cursor != cursor
+		if advanced {
+			cursor = page.Cursor
+		}
+
+		if page.HasMore {
+			if advanced {
+				stalled = false
+				if ctx.Err() != nil {
+					return nil
+				}
+				continue
+			}
+			// Fetching the same cursor again right away would never end, so
+			// wait for the next poll like when there are no more entries.
+			if !stalled {
+				fmt.Fprintf(warnings, "warning: more logs are available but the cursor did not advance\n")
+			}
+			stalled = true
+		}
+		if !flags.follow || !sleepContext(ctx, logsPollInterval) {
+			return nil
+		}
+	}
+}
+
+// logDeduper remembers the newest log entries that were seen, so that entries
+// returned again by a later page are not printed twice.
+type logDeduper struct {
+	newest time.Time
+	// atNewest holds the entries seen with the newest timestamp, which a
+	// later page may repeat along with new entries logged at the same time.
+	atNewest map[logEntryKey]bool
+}
+
+type logEntryKey struct {
+	level, message, revision string
+}
+
+// seen reports whether entry, or a later one, was seen before, and records it.
+func (d *logDeduper) seen(entry logEntry) bool {
+	key := logEntryKey{entry.Level, entry.Message, entry.Revision}
+	switch {
+	case entry.Timestamp.Before(d.newest):
+		return true
+	case entry.Timestamp.After(d.newest) || d.atNewest == nil:
+		d.newest = entry.Timestamp
+		d.atNewest = map[logEntryKey]bool{key: true}
+		return false
+	case d.atNewest[key]:
+		return true
+	}
+	d.atNewest[key] = true
+	return false
+}
+
+// fetchLogs fetches the page of log entries after cursor, or the first page
+// of entries logged since since if there is no cursor yet.
+func fetchLogs(client restClient, appName, revisionName string, since time.Time, cursor string) (logsPage, error) {
+	params := url.Values{}
+	if revisionName != "" {
+		params.Add("revision_name", revisionName)
+	}
+	if cursor != "" {
+		params.Add("cursor", cursor)
+	} else if !since.IsZero() {
+		params.Add("since", since.UTC().Format(time.RFC3339))
+	}
+
+	logsUrl := fmt.Sprintf("runtime/%s/deployment/logs", appName)
+	if len(params) > 0 {
+		logsUrl += "?" + params.Encode()
+	}
+
+	page := logsPage{}
+	err := client.Get(logsUrl, &page)
+	if err != nil {
+		return logsPage{}, fmt.Errorf("error fetching logs: %w", describeAPIError(err))
+	}
+	return page, nil
+}
+
+// retryableLogsError reports whether fetching logs may succeed if retried:
+// the connection dropped, the server failed, or requests were rate limited.
+func retryableLogsError(err error) bool {
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) {
+		return true
+	}
+	return httpErr.StatusCode >= http.StatusInternalServerError || httpErr.StatusCode == http.StatusTooManyRequests
+}
+
+// sleepContext waits for d, and reports false if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// parseSince parses a --since value: a duration before now, such as "90m" or
+// "2d", or an RFC 3339 timestamp.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value '%s': must be a duration such as '1h' or '2d', or a timestamp such as '2024-01-02T15:04:05Z'", value)
+}
+
+// logFilter selects the log entries that are printed.
+type logFilter struct {
+	// minLevel is the index in logLevels of the least severe level shown.
+	minLevel int
+	text     string
+	pattern  *regexp.Regexp
+}
+
+func newLogFilter(level, grep string, regex bool) (logFilter, error) {
+	filter := logFilter{}
+	if level != "" {
+		filter.minLevel = logLevelIndex(level)
+		if filter.minLevel < 0 {
+			return logFilter{}, fmt.Errorf("invalid --level value '%s': must be one of %s", level, strings.Join(logLevels, ", "))
+		}
+	}
+
+	if regex {
+		if grep == "" {
+			return logFilter{}, fmt.Errorf("--regex requires --grep")
+		}
+		pattern, err := regexp.Compile(grep)
+		if err != nil {
+			return logFilter{}, fmt.Errorf("invalid --grep regular expression: %v", err)
+		}
+		filter.pattern = pattern
+	} else {
+		filter.text = grep
+	}
+	return filter, nil
+}
+
+// logLevelIndex returns the index of level in logLevels, or -1 if it is not a
+// known level.
+func logLevelIndex(level string) int {
+	level = strings.ToLower(level)
+	if level == "warning" {
+		level = "warn"
+	}
+	for i, known := range logLevels {
+		if level == known {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f logFilter) match(entry logEntry) bool {
+	// Entries with an unknown level are only hidden when a level is asked for.
+	if f.minLevel > 0 && logLevelIndex(entry.Level) < f.minLevel {
+		return false
+	}
+	if f.pattern != nil {
+		return f.pattern.MatchString(entry.Message)
+	}
+	return strings.Contains(entry.Message, f.text)
+}
+
+// logPrinter prints log entries as colored lines or as NDJSON.
+type logPrinter struct {
+	w     io.Writer
+	json  bool
+	color bool
+}
+
+func (p logPrinter) print(entry logEntry) error {
+	if p.json {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("error encoding log entry: %v", err)
+		}
+		_, err = fmt.Fprintf(p.w, "%s\n", data)
+		return err
+	}
+
+	timestamp := entry.Timestamp.Format(time.RFC3339)
+	level := fmt.Sprintf("%-5s", strings.ToUpper(entry.Level))
+	if p.color {
+		timestamp = ansiDim + timestamp + ansiReset
+		if color, ok := logLevelColors[strings.ToLower(entry.Level)]; ok {
+			level = color + level + ansiReset
+		}
+	}
+
+	line := timestamp + " " + level
+	if entry.Revision != "" {
+		line += " [" + entry.Revision + "]"
+	}
+	_, err := fmt.Fprintf(p.w, "%s %s\n", line, strings.TrimRight(entry.Message, "\n"))
+	return err
+}
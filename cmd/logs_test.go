@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunLogs_Pages(t *testing.T) {
+	paths := []string{}
+	client := &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			paths = append(paths, path)
+			if len(paths) == 1 {
+				return mockGetResponse(`{"entries":[
+					{"timestamp":"2024-01-02T15:04:05Z","level":"info","message":"started","revision":"v2"},
+					{"timestamp":"2024-01-02T15:04:06Z","level":"debug","message":"cache warm"}
+				],"cursor":"c1","has_more":true}`)(path, resp)
+			}
+			return mockGetResponse(`{"entries":[
+				{"timestamp":"2024-01-02T15:04:07Z","level":"error","message":"request failed: timeout\n"}
+			],"cursor":"c2"}`)(path, resp)
+		},
+	}
+
+	var out strings.Builder
+	flags := logsCmdFlags{app: "my-app", revisionName: "v2", since: "2024-01-02T15:00:00Z", level: "info"}
+	err := runLogs(context.Background(), client, flags, logPrinter{w: &out}, &strings.Builder{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"runtime/my-app/deployment/logs?revision_name=v2&since=2024-01-02T15%3A00%3A00Z",
+		"runtime/my-app/deployment/logs?cursor=c1&revision_name=v2",
+	}, paths)
+	assert.Equal(t, "2024-01-02T15:04:05Z INFO  [v2] started\n2024-01-02T15:04:07Z ERROR request failed: timeout\n", out.String())
+}
+
+func TestRunLogs_FollowReconnects(t *testing.T) {
+	logsPollInterval = time.Millisecond
+	logsMaxBackoff = time.Millisecond
+	defer func() {
+		logsPollInterval = 2 * time.Second
+		logsMaxBackoff = 30 * time.Second
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	paths := []string{}
+	client := &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			calls++
+			paths = append(paths, path)
+			switch calls {
+			case 1:
+				return mockGetResponse(`{"entries":[{"timestamp":"2024-01-02T15:04:05Z","level":"info","message":"one"}],"cursor":"c1"}`)(path, resp)
+			case 2:
+				return fmt.Errorf("connection reset by peer")
+			case 3:
+				return &api.HTTPError{StatusCode: http.StatusBadGateway, Message: "Bad Gateway", Headers: http.Header{}}
+			default:
+				cancel()
+				return mockGetResponse(`{"entries":[{"timestamp":"2024-01-02T15:04:06Z","level":"info","message":"two"}],"cursor":"c2"}`)(path, resp)
+			}
+		},
+	}
+
+	var out, warnings strings.Builder
+	err := runLogs(ctx, client, logsCmdFlags{app: "my-app", follow: true, json: true}, logPrinter{w: &out, json: true}, &warnings)
+	require.NoError(t, err)
+
+	assert.Equal(t, "runtime/my-app/deployment/logs?cursor=c1", paths[3])
+	assert.Equal(t, `{"timestamp":"2024-01-02T15:04:05Z","level":"info","message":"one"}
+{"timestamp":"2024-01-02T15:04:06Z","level":"info","message":"two"}
+`, out.String())
+	assert.Contains(t, warnings.String(), "warning: error fetching logs: connection reset by peer, retrying in")
+	assert.Contains(t, warnings.String(), "warning: error fetching logs: HTTP 502: Bad Gateway, retrying in")
+}
+
+func TestRunLogs_CursorDoesNotAdvance(t *testing.T) {
+	logsPollInterval = time.Millisecond
+	defer func() { logsPollInterval = 2 * time.Second }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	client := &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			calls++
+			if calls > 20 {
+				return fmt.Errorf("too many requests for a stalled cursor")
+			}
+			if calls == 6 {
+				cancel()
+			}
+			return mockGetResponse(`{"entries":[{"timestamp":"2024-01-02T15:04:05Z","level":"info","message":"stuck"}],"cursor":"c1","has_more":true}`)(path, resp)
+		},
+	}
+
+	// Without --follow, fetching stops once the cursor stops advancing.
+	var out, warnings strings.Builder
+	err := runLogs(context.Background(), client, logsCmdFlags{app: "my-app"}, logPrinter{w: &out}, &warnings)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "2024-01-02T15:04:05Z INFO  stuck\n", out.String())
+	assert.Equal(t, "warning: more logs are available but the cursor did not advance\n", warnings.String())
+
+	// With --follow, the same cursor is fetched again at every poll, with a
+	// single warning, and its entries are only printed once.
+	out.Reset()
+	warnings.Reset()
+	err = runLogs(ctx, client, logsCmdFlags{app: "my-app", follow: true}, logPrinter{w: &out}, &warnings)
+	require.NoError(t, err)
+	assert.Equal(t, 6, calls)
+	assert.Equal(t, "2024-01-02T15:04:05Z INFO  stuck\n", out.String())
+	assert.Equal(t, "warning: more logs are available but the cursor did not advance\n", warnings.String())
+}
+
+func TestRunLogs_FollowWithoutCursor(t *testing.T) {
+	logsPollInterval = time.Millisecond
+	defer func() { logsPollInterval = 2 * time.Second }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	paths := []string{}
+	client := &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			calls++
+			paths = append(paths, path)
+			switch calls {
+			case 1, 2:
+				return mockGetResponse(`{"entries":[
+					{"timestamp":"2024-01-02T15:04:05Z","level":"info","message":"one"},
+					{"timestamp":"2024-01-02T15:04:06Z","level":"info","message":"two"}
+				]}`)(path, resp)
+			default:
+				cancel()
+				return mockGetResponse(`{"entries":[
+					{"timestamp":"2024-01-02T15:04:05Z","level":"info","message":"one"},
+					{"timestamp":"2024-01-02T15:04:06Z","level":"info","message":"two"},
+					{"timestamp":"2024-01-02T15:04:06Z","level":"warn","message":"three"},
+					{"timestamp":"2024-01-02T15:04:07Z","level":"info","message":"four"}
+				]}`)(path, resp)
+			}
+		},
+	}
+
+	var out strings.Builder
+	err := runLogs(ctx, client, logsCmdFlags{app: "my-app", follow: true}, logPrinter{w: &out}, &strings.Builder{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, "runtime/my-app/deployment/logs", paths[2])
+	assert.Equal(t, `2024-01-02T15:04:05Z INFO  one
+2024-01-02T15:04:06Z INFO  two
+2024-01-02T15:04:06Z WARN  three
+2024-01-02T15:04:07Z INFO  four
+`, out.String())
+}
+
+func TestRunLogs_FollowStopsOnClientError(t *testing.T) {
+	client := &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			return &api.HTTPError{StatusCode: http.StatusNotFound, Message: "Not Found", Headers: http.Header{}}
+		},
+	}
+
+	err := runLogs(context.Background(), client, logsCmdFlags{app: "my-app", follow: true}, logPrinter{w: &strings.Builder{}}, &strings.Builder{})
+	require.EqualError(t, err, "error fetching logs: HTTP 404: Not Found")
+}
+
+func TestNewLogFilter(t *testing.T) {
+	entries := []logEntry{
+		{Level: "debug", Message: "cache warm"},
+		{Level: "info", Message: "GET /api/users 200"},
+		{Level: "WARNING", Message: "slow query"},
+		{Level: "error", Message: "GET /api/orders 504 timeout"},
+		{Level: "trace", Message: "entered handler"},
+	}
+
+	tests := []struct {
+		name     string
+		level    string
+		grep     string
+		regex    bool
+		expected []string
+	}{
+		{name: "all", expected: []string{"cache warm", "GET /api/users 200", "slow query", "GET /api/orders 504 timeout", "entered handler"}},
+		{name: "level", level: "warn", expected: []string{"slow query", "GET /api/orders 504 timeout"}},
+		{name: "text", grep: "GET /api", expected: []string{"GET /api/users 200", "GET /api/orders 504 timeout"}},
+		{name: "regex", grep: `\s5\d\d\s`, regex: true, expected: []string{"GET /api/orders 504 timeout"}},
+		{name: "level and text", level: "info", grep: "GET", expected: []string{"GET /api/users 200", "GET /api/orders 504 timeout"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newLogFilter(tt.level, tt.grep, tt.regex)
+			require.NoError(t, err)
+
+			matched := []string{}
+			for _, entry := range entries {
+				if filter.match(entry) {
+					matched = append(matched, entry.Message)
+				}
+			}
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+}
+
+func TestNewLogFilter_Invalid(t *testing.T) {
+	_, err := newLogFilter("fatal", "", false)
+	require.EqualError(t, err, "invalid --level value 'fatal': must be one of debug, info, warn, error")
+
+	_, err = newLogFilter("", "(", true)
+	require.ErrorContains(t, err, "invalid --grep regular expression")
+
+	_, err = newLogFilter("", "", true)
+	require.EqualError(t, err, "--regex requires --grep")
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{value: "1h", expected: now.Add(-time.Hour)},
+		{value: "90m", expected: now.Add(-90 * time.Minute)},
+		{value: "2d", expected: now.AddDate(0, 0, -2)},
+		{value: "2023-12-31T23:00:00Z", expected: time.Date(2023, 12, 31, 23, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			since, err := parseSince(tt.value, now)
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(since), "expected %s, got %s", tt.expected, since)
+		})
+	}
+
+	_, err := parseSince("yesterday", now)
+	require.ErrorContains(t, err, "invalid --since value 'yesterday'")
+}
+
+func TestLogPrinter_Color(t *testing.T) {
+	var out strings.Builder
+	printer := logPrinter{w: &out, color: true}
+	require.NoError(t, printer.print(logEntry{Timestamp: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), Level: "error", Message: "boom"}))
+	assert.Equal(t, "\x1b[2m2024-01-02T15:04:05Z\x1b[0m \x1b[31mERROR\x1b[0m boom\n", out.String())
+}
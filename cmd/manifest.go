@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// bundleManifestPath is where the manifest of a bundle is stored inside it.
+// The manifest lists every file of the bundle with its size and SHA-256
+// digest, so that a bundle can be checked against what was packed.
+const bundleManifestPath = ".runtime/manifest.json"
+
+// bundleManifestVersion is the version of the manifest format.
+const bundleManifestVersion = 1
+
+// maxBundleManifestSize is the largest manifest read from a bundle, with room
+// for maxArchiveEntries files with long paths and precompressed variants.
+const maxBundleManifestSize = maxArchiveEntries << 10
+
+// bundleManifest is the content of bundleManifestPath.
+type bundleManifest struct {
+	Version int            `json:"version"`
+	Files   []manifestFile `json:"files"`
+}
+
+// manifestFile is a file or symbolic link listed in a bundle manifest.
+type manifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Link is the target of a symbolic link preserved in the bundle.
+	Link string `json:"link,omitempty"`
+	// Encodings maps a content encoding, "br" or "gzip", to the precompressed
+	// variant of the file that is served for it.
+	Encodings map[string]string `json:"encodings,omitempty"`
+}
+
+// writeBundleManifest adds the manifest to a bundle.
+func writeBundleManifest(zipWriter *zip.Writer, manifest bundleManifest, opts bundleOptions) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding bundle manifest: %w", err)
+	}
+
+	_, err = writeGeneratedFile(zipWriter, bundleManifestPath, append(data, '\n'), opts)
+	return err
+}
+
+// readBundleManifest returns the manifest of a bundle, or nil if it does not
+// have one.
+func readBundleManifest(reader *zip.Reader) (*bundleManifest, error) {
+	for _, f := range reader.File {
+		if f.Name != bundleManifestPath {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("error reading bundle manifest: %w", err)
+		}
+		defer rc.Close()
+
+		data, err := io.ReadAll(io.LimitReader(rc, maxBundleManifestSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("error reading bundle manifest: %w", err)
+		}
+		if len(data) > maxBundleManifestSize {
+			return nil, fmt.Errorf("bundle manifest is larger than %s", formatByteSize(maxBundleManifestSize))
+		}
+
+		manifest := &bundleManifest{}
+		err = json.Unmarshal(data, manifest)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing bundle manifest: %w", err)
+		}
+		return manifest, nil
+	}
+	return nil, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 )
 
 // mockRESTClient implements the restClient interface for testing.
@@ -14,6 +15,8 @@ type mockRESTClient struct {
 	postFunc   func(path string, body io.Reader, resp interface{}) error
 	patchFunc  func(path string, body io.Reader, resp interface{}) error
 	doFunc     func(method string, path string, body io.Reader, resp interface{}) error
+	// requestFunc returns the raw response of a request.
+	requestFunc func(method string, path string, body io.Reader) (*http.Response, error)
 }
 
 func (m *mockRESTClient) Get(path string, resp interface{}) error {
@@ -58,6 +61,13 @@ func (m *mockRESTClient) Do(method string, path string, body io.Reader, resp int
 	return fmt.Errorf("Do not implemented")
 }
 
+func (m *mockRESTClient) Request(method string, path string, body io.Reader) (*http.Response, error) {
+	if m.requestFunc != nil {
+		return m.requestFunc(method, path, body)
+	}
+	return nil, fmt.Errorf("Request not implemented")
+}
+
 // mockGetResponse is a helper that configures the mock to return a JSON-decoded response.
 func mockGetResponse(jsonBody string) func(path string, resp interface{}) error {
 	return func(path string, resp interface{}) error {
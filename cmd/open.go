@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/browser"
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+type openCmdFlags struct {
+	app          string
+	revisionName string
+	config       string
+	settings     bool
+	noBrowser    bool
+}
+
+// urlBrowser opens URLs in a web browser. It is implemented by
+// browser.Browser.
+type urlBrowser interface {
+	Browse(url string) error
+}
+
+func init() {
+	openCmdFlags := openCmdFlags{}
+	openCmd := &cobra.Command{
+		Use:   "open",
+		Short: "Open a GitHub Runtime app in the browser",
+		Long: heredoc.Doc(`
+			Open a GitHub Runtime app, or with --settings the page to manage it on GitHub, in the web browser.
+			The app is resolved the same way as by 'gh runtime get': from the --app flag, the --config flag,
+			or runtime.config.json in the current directory.
+			The URL is printed instead if --no-browser is set or no browser can be started. The browser can
+			be chosen with the GH_BROWSER or BROWSER environment variables, or the 'browser' setting of gh.
+		`),
+		Example: heredoc.Doc(`
+			$ gh runtime open
+			# => Opens the app of runtime.config.json in the current directory
+
+			$ gh runtime open --app my-app --revision-name v2
+			# => Opens revision 'v2' of the app with ID 'my-app'
+
+			$ gh runtime open --settings
+			# => Opens the page to manage the app on GitHub
+
+			$ gh runtime open --no-browser
+			# => Prints the URL of the app
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newRESTClient()
+			if err != nil {
+				return fmt.Errorf("failed creating REST client: %v", err)
+			}
+
+			var b urlBrowser
+			if !openCmdFlags.noBrowser {
+				b = browser.New("", os.Stdout, os.Stderr)
+			}
+			return runOpen(client, b, openCmdFlags, os.Stdout, os.Stderr)
+		},
+	}
+
+	openCmd.Flags().StringVarP(&openCmdFlags.app, "app", "a", "", "The app ID to open")
+	openCmd.Flags().StringVarP(&openCmdFlags.config, "config", "c", "", "Path to runtime config file")
+	openCmd.Flags().StringVarP(&openCmdFlags.revisionName, "revision-name", "r", "", "The revision name to open")
+	openCmd.Flags().BoolVar(&openCmdFlags.settings, "settings", false, "Open the page to manage the app on GitHub instead of the app")
+	openCmd.Flags().BoolVar(&openCmdFlags.noBrowser, "no-browser", false, "Print the URL instead of opening it")
+	rootCmd.AddCommand(openCmd)
+}
+
+// runOpen opens the URL of the app in b, or prints it to out if b is nil or
+// fails to start.
+func runOpen(client restClient, b urlBrowser, flags openCmdFlags, out, warnings io.Writer) error {
+	appName, err := config.ResolveAppName(flags.app, flags.config)
+	if err != nil {
+		return err
+	}
+
+	response := serverResponse{}
+	err = client.Get(deploymentURL(appName, "", revisionQuery(flags.revisionName, "")), &response)
+	if err != nil {
+		return fmt.Errorf("retrieving app details: %w", describeAPIError(err))
+	}
+
+	target := response.AppUrl
+	if flags.settings {
+		target = response.HtmlUrl
+		if target == "" {
+			return fmt.Errorf("no settings page was returned for the app")
+		}
+	}
+	if target == "" {
+		return fmt.Errorf("no URL was returned for the app")
+	}
+
+	if b == nil {
+		fmt.Fprintln(out, target)
+		return nil
+	}
+
+	fmt.Fprintf(warnings, "Opening %s in your browser\n", target)
+	err = b.Browse(target)
+	if err != nil {
+		fmt.Fprintf(warnings, "warning: could not open a browser: %v\n", err)
+		fmt.Fprintln(out, target)
+	}
+	return nil
+}
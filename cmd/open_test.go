@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBrowser records the URLs it is asked to open.
+type fakeBrowser struct {
+	opened []string
+	err    error
+}
+
+func (b *fakeBrowser) Browse(url string) error {
+	b.opened = append(b.opened, url)
+	return b.err
+}
+
+func newOpenClient(capturedPath *string) *mockRESTClient {
+	return &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			*capturedPath = path
+			return mockGetResponse(`{"app_url":"https://my-app.example.com","html_url":"https://github.com/runtime/my-app"}`)(path, resp)
+		},
+	}
+}
+
+func TestRunOpen_OpensApp(t *testing.T) {
+	var capturedPath string
+	b := &fakeBrowser{}
+	var out, warnings strings.Builder
+
+	err := runOpen(newOpenClient(&capturedPath), b, openCmdFlags{app: "my-app", revisionName: "v2"}, &out, &warnings)
+	require.NoError(t, err)
+	assert.Equal(t, "runtime/my-app/deployment?revision_name=v2", capturedPath)
+	assert.Equal(t, []string{"https://my-app.example.com"}, b.opened)
+	assert.Empty(t, out.String())
+	assert.Equal(t, "Opening https://my-app.example.com in your browser\n", warnings.String())
+}
+
+func TestRunOpen_Settings(t *testing.T) {
+	var capturedPath string
+	b := &fakeBrowser{}
+	var out, warnings strings.Builder
+
+	err := runOpen(newOpenClient(&capturedPath), b, openCmdFlags{app: "my-app", settings: true}, &out, &warnings)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://github.com/runtime/my-app"}, b.opened)
+}
+
+func TestRunOpen_NoBrowser(t *testing.T) {
+	var capturedPath string
+	var out, warnings strings.Builder
+
+	err := runOpen(newOpenClient(&capturedPath), nil, openCmdFlags{app: "my-app"}, &out, &warnings)
+	require.NoError(t, err)
+	assert.Equal(t, "https://my-app.example.com\n", out.String())
+	assert.Empty(t, warnings.String())
+}
+
+func TestRunOpen_BrowserFails(t *testing.T) {
+	var capturedPath string
+	b := &fakeBrowser{err: fmt.Errorf("exec: \"xdg-open\": executable file not found in $PATH")}
+	var out, warnings strings.Builder
+
+	err := runOpen(newOpenClient(&capturedPath), b, openCmdFlags{app: "my-app"}, &out, &warnings)
+	require.NoError(t, err)
+	assert.Equal(t, "https://my-app.example.com\n", out.String())
+	assert.Contains(t, warnings.String(), "warning: could not open a browser: exec: \"xdg-open\"")
+}
+
+func TestRunOpen_NoSettingsPage(t *testing.T) {
+	client := &mockRESTClient{getFunc: mockGetResponse(`{"app_url":"https://my-app.example.com"}`)}
+	err := runOpen(client, &fakeBrowser{}, openCmdFlags{app: "my-app", settings: true}, &strings.Builder{}, &strings.Builder{})
+	require.EqualError(t, err, "no settings page was returned for the app")
+}
+
+func TestRunOpen_APIError(t *testing.T) {
+	client := &mockRESTClient{getFunc: mockGetError("server error")}
+	err := runOpen(client, &fakeBrowser{}, openCmdFlags{app: "my-app"}, &strings.Builder{}, &strings.Builder{})
+	require.EqualError(t, err, "retrieving app details: server error")
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+type packCmdFlags struct {
+	bundle deployCmdFlags
+	out    string
+}
+
+func init() {
+	packCmdFlags := packCmdFlags{}
+	packCmd := &cobra.Command{
+		Use:   "pack",
+		Short: "Build a bundle without deploying it",
+		Long: heredoc.Docf(`
+			Builds the exact bundle that deploy would upload and writes it to --out, without contacting GitHub.
+			The directory and build command are resolved the same way as for deploy, and the bundle includes
+			a manifest of every file at %[1]s%[2]s%[1]s.
+			Check the bundle with 'gh runtime verify' and deploy it with 'gh runtime deploy --archive'.
+			If a signing key is given with --signing-key or GH_RUNTIME_SIGNING_KEY, signed provenance for the
+			bundle is written next to it, to the path of the bundle with '%[3]s' appended.
+			With --sbom, an SPDX SBOM of the bundle is written next to it with '%[4]s' appended.
+		`, "`", bundleManifestPath, provenanceExt, sbomExt),
+		Example: heredoc.Doc(`
+			$ gh runtime pack --dir ./dist --out bundle.zip
+			# => Writes the bundle of the 'dist' directory to bundle.zip
+
+			$ gh runtime pack --out bundle.zip && gh runtime verify bundle.zip && gh runtime deploy --archive bundle.zip
+			# => Builds, checks and then deploys a bundle
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPack(packCmdFlags)
+		},
+	}
+	addBundleFlags(packCmd, &packCmdFlags.bundle)
+	packCmd.Flags().StringVarP(&packCmdFlags.out, "out", "o", "", "Path of the bundle to write")
+	rootCmd.AddCommand(packCmd)
+}
+
+func runPack(flags packCmdFlags) error {
+	if flags.out == "" {
+		return fmt.Errorf("--out flag is required")
+	}
+
+	d, err := resolveBundleIn(".", flags.bundle)
+	if err != nil {
+		return err
+	}
+	// The app is only passed to the build command, so a bundle can be packed
+	// before the app exists.
+	d.appName, _ = config.ResolveAppName(d.flags.app, d.flags.config)
+
+	outDir, err := filepath.Abs(filepath.Dir(flags.out))
+	if err != nil {
+		return fmt.Errorf("error resolving '%s': %v", flags.out, err)
+	}
+	bundleDir, err := filepath.Abs(d.flags.dir)
+	if err != nil {
+		return fmt.Errorf("error resolving directory '%s': %v", d.flags.dir, err)
+	}
+	if isWithinDir(bundleDir, outDir) {
+		return fmt.Errorf("--out must not be inside the directory being packed '%s'", d.flags.dir)
+	}
+
+	// Write next to the destination and rename, so that a failed pack never
+	// leaves a partial bundle behind.
+	tmpFile, err := os.CreateTemp(outDir, ".gh-runtime-pack-*.zip")
+	if err != nil {
+		return fmt.Errorf("error creating zip file: %v", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	startedOn := time.Now()
+	entries, err := d.writeBundle(tmpPath)
+	if err != nil {
+		return err
+	}
+	finishedOn := time.Now()
+
+	zipInfo, err := os.Stat(tmpPath)
+	if err != nil {
+		return fmt.Errorf("error reading zip file '%s': %v", tmpPath, err)
+	}
+	report := newSizeReport(entries, zipInfo.Size())
+	report.write(os.Stdout, sizeReportTopN)
+
+	err = d.budget.check(report)
+	if err != nil {
+		return err
+	}
+
+	// CreateTemp only grants access to the owner, but a bundle is meant to be
+	// shared.
+	err = os.Chmod(tmpPath, 0o644)
+	if err != nil {
+		return fmt.Errorf("error writing bundle '%s': %v", flags.out, err)
+	}
+	err = os.Rename(tmpPath, flags.out)
+	if err != nil {
+		return fmt.Errorf("error writing bundle '%s': %v", flags.out, err)
+	}
+	fmt.Printf("Packed bundle to %s\n", flags.out)
+
+	if d.signingKey != nil {
+		envelope, err := d.signProvenance(flags.out, filepath.Base(flags.out), startedOn, finishedOn)
+		if err != nil {
+			return err
+		}
+		err = writeProvenance(flags.out+provenanceExt, envelope)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Wrote signed provenance to %s\n", flags.out+provenanceExt)
+	}
+
+	if d.flags.sbom {
+		bom, err := d.generateSBOM(flags.out, filepath.Base(flags.out))
+		if err != nil {
+			return err
+		}
+		err = writeSBOM(flags.out+sbomExt, bom)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Wrote SBOM to %s\n", flags.out+sbomExt)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPack(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{
+		"index.html":    "<html></html>",
+		"assets/app.js": "console.log('hi')",
+	})
+	out := filepath.Join(tmp, "bundle.zip")
+
+	err := runPack(packCmdFlags{bundle: deployCmdFlags{dir: src, reproducible: true}, out: out})
+	require.NoError(t, err)
+
+	files := zipEntryNames(t, out)
+	assert.Contains(t, files, "index.html")
+	assert.Contains(t, files, "assets/app.js")
+	require.Contains(t, files, bundleManifestPath)
+	assert.Contains(t, readZipEntry(t, files[bundleManifestPath]), `"path": "assets/app.js"`)
+
+	result, err := verifyBundle(out, bundleBudget{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Problems)
+	assert.Empty(t, result.Warnings)
+	assert.Equal(t, 2, result.Files)
+}
+
+func TestRunPack_MatchesDeploy(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{"index.html": "<html></html>", "about/index.html": "about"})
+	out := filepath.Join(tmp, "bundle.zip")
+
+	require.NoError(t, runPack(packCmdFlags{bundle: deployCmdFlags{dir: src, reproducible: true}, out: out}))
+	packed, err := os.ReadFile(out)
+	require.NoError(t, err)
+
+	var deployed []byte
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			deployed, err = io.ReadAll(body)
+			return err
+		},
+	}
+	require.NoError(t, runDeploy(client, deployCmdFlags{dir: src, app: "my-app", reproducible: true}))
+	assert.Equal(t, packed, deployed, "pack should write the bundle deploy uploads")
+}
+
+func TestRunPack_ExceedsBudget(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{"index.html": string(make([]byte, 2048))})
+	out := filepath.Join(tmp, "bundle.zip")
+
+	err := runPack(packCmdFlags{bundle: deployCmdFlags{dir: src, maxFileSize: "1KiB"}, out: out})
+	require.ErrorContains(t, err, "exceeds the maximum file size")
+	_, err = os.Stat(out)
+	assert.True(t, os.IsNotExist(err), "no bundle should be written")
+}
+
+func TestRunPack_OutInsideDir(t *testing.T) {
+	src := t.TempDir()
+	writeTestTree(t, src, map[string]string{"index.html": "<html></html>"})
+
+	err := runPack(packCmdFlags{bundle: deployCmdFlags{dir: src}, out: filepath.Join(src, "bundle.zip")})
+	require.ErrorContains(t, err, "--out must not be inside the directory being packed")
+}
+
+func TestRunPack_NoOut(t *testing.T) {
+	err := runPack(packCmdFlags{bundle: deployCmdFlags{dir: "dist"}})
+	require.ErrorContains(t, err, "--out flag is required")
+}
+
+func TestRunPack_ManyFiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("packs tens of thousands of files")
+	}
+
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	files := map[string]string{"index.html": "<html></html>"}
+	for i := 0; i < 40000; i++ {
+		files[fmt.Sprintf("assets/chunks/%03d/chunk-%05d.js", i/1000, i)] = "x"
+	}
+	writeTestTree(t, src, files)
+	out := filepath.Join(tmp, "bundle.zip")
+
+	require.NoError(t, runPack(packCmdFlags{bundle: deployCmdFlags{dir: src, reproducible: true}, out: out}))
+
+	// The manifest of that many files is larger than any other file the
+	// bundle holds, and verify must still read it.
+	manifest := readZipEntry(t, zipEntryNames(t, out)[bundleManifestPath])
+	assert.Greater(t, len(manifest), 4<<20)
+
+	result, err := verifyBundle(out, bundleBudget{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Problems)
+	assert.Equal(t, len(files), result.Files)
+}
+
+func TestRunPack_Mode(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{"index.html": "<html></html>"})
+	out := filepath.Join(tmp, "bundle.zip")
+
+	require.NoError(t, runPack(packCmdFlags{bundle: deployCmdFlags{dir: src, reproducible: true}, out: out}))
+
+	info, err := os.Stat(out)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/github/gh-runtime-cli/internal/git"
+	"github.com/github/gh-runtime-cli/internal/preview"
+	"github.com/spf13/cobra"
+)
+
+// defaultPreviewTTL is how long preview revisions are kept by preview cleanup.
+const defaultPreviewTTL = 7 * 24 * time.Hour
+
+type previewDeployCmdFlags struct {
+	deploy deployCmdFlags
+	pr     int
+	branch string
+}
+
+type previewCleanupCmdFlags struct {
+	app    string
+	config string
+	repo   string
+	ttl    time.Duration
+	dryRun bool
+}
+
+// pullRequest is the part of a pull request returned by the GitHub API that
+// preview cleanup needs.
+type pullRequest struct {
+	State string `json:"state"`
+}
+
+func init() {
+	previewCmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Manage preview deployments of a GitHub Runtime app",
+		Long: heredoc.Docf(`
+			Deploy pull requests and branches to their own preview revision, and clean them up.
+			Preview revisions are named %[1]spreview-pr-<number>%[1]s for pull requests and
+			%[1]spreview-<branch>%[1]s for branches, with the branch name lowercased and every character
+			other than letters and digits replaced with a hyphen. Branch names that are too long, or that
+			start with %[1]spr-%[1]s, are suffixed with a hash so they never collide with another preview.
+		`, "`"),
+	}
+
+	deployFlags := previewDeployCmdFlags{}
+	deployCmd := &cobra.Command{
+		Use:   "deploy",
+		Short: "Deploy the current pull request or branch to a preview revision",
+		Long: heredoc.Doc(`
+			Deploys to the preview revision of the current pull request or branch and prints its URL.
+			The --pr and --branch flags take precedence. Otherwise the pull request is read from GITHUB_REF in
+			pull request workflows, or the branch from GITHUB_HEAD_REF or the branch checked out in the
+			current directory.
+			The directory, app and build command are resolved the same way as for deploy.
+		`),
+		Example: heredoc.Doc(`
+			$ gh runtime preview deploy --dir ./dist
+			# => Deploys the 'dist' directory to the preview revision of the current branch
+
+			$ gh runtime preview deploy --pr 42
+			# => Deploys to the 'preview-pr-42' revision
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newRESTClient()
+			if err != nil {
+				return fmt.Errorf("failed creating REST client: %v", err)
+			}
+
+			_, err = runPreviewDeploy(client, deployFlags)
+			return err
+		},
+	}
+	addDeployFlags(deployCmd, &deployFlags.deploy)
+	deployCmd.Flags().IntVar(&deployFlags.pr, "pr", 0, "The pull request number to deploy a preview for")
+	deployCmd.Flags().StringVar(&deployFlags.branch, "branch", "", "The branch to deploy a preview for")
+
+	cleanupFlags := previewCleanupCmdFlags{}
+	cleanupCmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Delete stale preview revisions",
+		Long: heredoc.Doc(`
+			Deletes preview revisions whose pull request is closed or that are older than --ttl.
+			Pull requests are looked up in --repo, or in the repository of the current directory.
+			Use --ttl 0 to only delete previews of closed pull requests.
+		`),
+		Example: heredoc.Doc(`
+			$ gh runtime preview cleanup --app my-app
+			# => Deletes previews of closed pull requests and previews older than 7 days
+
+			$ gh runtime preview cleanup --ttl 48h --dry-run
+			# => Lists the previews that would be deleted without deleting them
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newRESTClient()
+			if err != nil {
+				return fmt.Errorf("failed creating REST client: %v", err)
+			}
+
+			_, err = runPreviewCleanup(client, cleanupFlags, time.Now())
+			return err
+		},
+	}
+	cleanupCmd.Flags().StringVarP(&cleanupFlags.app, "app", "a", "", "The app ID")
+	cleanupCmd.Flags().StringVarP(&cleanupFlags.config, "config", "c", "", "Path to runtime config file")
+	cleanupCmd.Flags().StringVarP(&cleanupFlags.repo, "repo", "R", "", "The repository of the pull requests, as OWNER/REPO")
+	cleanupCmd.Flags().DurationVar(&cleanupFlags.ttl, "ttl", defaultPreviewTTL, "Delete previews older than this")
+	cleanupCmd.Flags().BoolVar(&cleanupFlags.dryRun, "dry-run", false, "Print the previews that would be deleted without deleting them")
+
+	previewCmd.AddCommand(deployCmd, cleanupCmd)
+	rootCmd.AddCommand(previewCmd)
+}
+
+// runPreviewDeploy deploys to the preview revision of the current pull request
+// or branch and returns its URL.
+func runPreviewDeploy(client restClient, flags previewDeployCmdFlags) (string, error) {
+	revisionName, err := previewRevisionName(flags.pr, flags.branch)
+	if err != nil {
+		return "", err
+	}
+
+	deployFlags := flags.deploy
+	deployFlags.revisionName = revisionName
+	d, err := resolveDeployment(deployFlags)
+	if err != nil {
+		return "", err
+	}
+
+	err = d.run(client)
+	if err != nil {
+		return "", err
+	}
+	if deployFlags.dryRun {
+		return "", nil
+	}
+
+	appUrl, err := runGet(client, getCmdFlags{app: d.appName, revisionName: revisionName})
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Printf("Preview '%s' is live at %s\n", revisionName, appUrl)
+	return appUrl, nil
+}
+
+// previewRevisionName returns the preview revision name for the pull request
+// or branch being deployed.
+func previewRevisionName(pr int, branch string) (string, error) {
+	if pr < 0 {
+		return "", fmt.Errorf("invalid pull request number %d", pr)
+	}
+	if pr > 0 {
+		return preview.ForPullRequest(pr), nil
+	}
+	if branch != "" {
+		return preview.ForBranch(branch)
+	}
+	if number, ok := preview.PullRequestFromRef(os.Getenv("GITHUB_REF")); ok {
+		return preview.ForPullRequest(number), nil
+	}
+
+	branch = os.Getenv("GITHUB_HEAD_REF")
+	if branch == "" {
+		current, err := git.CurrentBranch(".")
+		if err != nil {
+			return "", fmt.Errorf("could not determine the pull request or branch to preview, use --pr or --branch: %v", err)
+		}
+		branch = current
+	}
+	if branch == "" {
+		return "", fmt.Errorf("could not determine the pull request or branch to preview, use --pr or --branch: HEAD is detached")
+	}
+
+	return preview.ForBranch(branch)
+}
+
+// runPreviewCleanup deletes the preview revisions whose pull request is closed
+// or that are older than the TTL, and returns them. With --dry-run nothing is
+// deleted.
+func runPreviewCleanup(client restClient, flags previewCleanupCmdFlags, now time.Time) ([]revision, error) {
+	appName, err := config.ResolveAppName(flags.app, flags.config)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := listRevisions(client, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	var repo *repository.Repository
+	stale := []revision{}
+	for _, rev := range revisions {
+		if !preview.IsPreview(rev.Name) {
+			continue
+		}
+
+		reason := ""
+		if number, ok := preview.PullRequestNumber(rev.Name); ok {
+			if repo == nil {
+				resolved, err := resolvePreviewRepo(flags.repo)
+				if err != nil {
+					return nil, err
+				}
+				repo = &resolved
+			}
+			closed, err := pullRequestClosed(client, *repo, number)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			} else if closed {
+				reason = fmt.Sprintf("pull request #%d is closed", number)
+			}
+		}
+		if reason == "" && flags.ttl > 0 {
+			// The age of a revision without a creation time is unknown, so it
+			// is kept rather than treated as infinitely old.
+			if rev.CreatedAt.IsZero() {
+				fmt.Fprintf(os.Stderr, "warning: creation time of preview '%s' is unknown, not checking its age\n", rev.Name)
+			} else if now.Sub(rev.CreatedAt) > flags.ttl {
+				reason = fmt.Sprintf("older than %s", flags.ttl)
+			}
+		}
+		if reason == "" {
+			continue
+		}
+
+		if flags.dryRun {
+			fmt.Printf("Would delete preview '%s': %s\n", rev.Name, reason)
+		} else {
+			err = deleteRevision(client, appName, rev.Name)
+			if err != nil {
+				return stale, err
+			}
+			fmt.Printf("Deleted preview '%s': %s\n", rev.Name, reason)
+		}
+		stale = append(stale, rev)
+	}
+
+	if len(stale) == 0 {
+		fmt.Printf("No stale previews of app '%s'\n", appName)
+	}
+	return stale, nil
+}
+
+// resolvePreviewRepo returns the repository whose pull requests previews are
+// deployed from.
+func resolvePreviewRepo(repoFlag string) (repository.Repository, error) {
+	if repoFlag != "" {
+		repo, err := repository.Parse(repoFlag)
+		if err != nil {
+			return repository.Repository{}, fmt.Errorf("invalid repository '%s': %v", repoFlag, err)
+		}
+		return repo, nil
+	}
+
+	repo, err := repository.Current()
+	if err != nil {
+		return repository.Repository{}, fmt.Errorf("could not determine the repository of the pull requests, use --repo: %v", err)
+	}
+	return repo, nil
+}
+
+func pullRequestClosed(client restClient, repo repository.Repository, number int) (bool, error) {
+	pr := pullRequest{}
+	err := client.Get(fmt.Sprintf("repos/%s/%s/pulls/%d", repo.Owner, repo.Name, number), &pr)
+	if err != nil {
+		return false, fmt.Errorf("error retrieving pull request #%d: %w", number, describeAPIError(err))
+	}
+	return pr.State == "closed", nil
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const previewRevisionsJSON = `[
+	{"revision_name":"preview-pr-1","created_at":"2024-05-09T10:00:00Z"},
+	{"revision_name":"preview-pr-2","created_at":"2024-05-09T10:00:00Z"},
+	{"revision_name":"preview-old-branch","created_at":"2024-04-01T10:00:00Z"},
+	{"revision_name":"preview-new-branch","created_at":"2024-05-09T10:00:00Z"},
+	{"revision_name":"preview-unknown-age"},
+	{"revision_name":"staging","created_at":"2024-01-01T10:00:00Z"}
+]`
+
+func TestPreviewRevisionName(t *testing.T) {
+	t.Setenv("GITHUB_REF", "")
+	t.Setenv("GITHUB_HEAD_REF", "")
+
+	name, err := previewRevisionName(42, "")
+	require.NoError(t, err)
+	assert.Equal(t, "preview-pr-42", name)
+
+	name, err = previewRevisionName(0, "feature/Login")
+	require.NoError(t, err)
+	assert.Equal(t, "preview-feature-login", name)
+
+	_, err = previewRevisionName(-1, "")
+	require.ErrorContains(t, err, "invalid pull request number")
+}
+
+func TestPreviewRevisionName_FromEnvironment(t *testing.T) {
+	t.Setenv("GITHUB_REF", "refs/pull/7/merge")
+	t.Setenv("GITHUB_HEAD_REF", "feature/login")
+
+	name, err := previewRevisionName(0, "")
+	require.NoError(t, err)
+	assert.Equal(t, "preview-pr-7", name)
+
+	t.Setenv("GITHUB_REF", "refs/heads/main")
+	name, err = previewRevisionName(0, "")
+	require.NoError(t, err)
+	assert.Equal(t, "preview-feature-login", name)
+}
+
+func TestPreviewRevisionName_FlagsOverrideEnvironment(t *testing.T) {
+	t.Setenv("GITHUB_REF", "refs/pull/7/merge")
+	t.Setenv("GITHUB_HEAD_REF", "feature/login")
+
+	name, err := previewRevisionName(0, "release/1.2")
+	require.NoError(t, err)
+	assert.Equal(t, "preview-release-1-2", name)
+
+	name, err = previewRevisionName(9, "")
+	require.NoError(t, err)
+	assert.Equal(t, "preview-pr-9", name)
+}
+
+func TestRunPreviewDeploy(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
+	t.Setenv("GITHUB_REF", "")
+	t.Setenv("GITHUB_HEAD_REF", "")
+
+	tmp := t.TempDir()
+	writeTestTree(t, tmp, map[string]string{"index.html": "<h1>preview</h1>"})
+
+	var postPath, getPath string
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			postPath = path
+			return nil
+		},
+		getFunc: func(path string, resp interface{}) error {
+			getPath = path
+			return json.Unmarshal([]byte(`{"app_url":"https://preview-pr-3.example.com"}`), resp)
+		},
+	}
+
+	appUrl, err := runPreviewDeploy(client, previewDeployCmdFlags{
+		deploy: deployCmdFlags{dir: tmp, app: "my-app"},
+		pr:     3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "runtime/my-app/deployment/bundle?revision_name=preview-pr-3", postPath)
+	assert.Equal(t, "runtime/my-app/deployment?revision_name=preview-pr-3", getPath)
+	assert.Equal(t, "https://preview-pr-3.example.com", appUrl)
+}
+
+func TestRunPreviewDeploy_DeployError(t *testing.T) {
+	tmp := t.TempDir()
+	writeTestTree(t, tmp, map[string]string{"index.html": "hi"})
+
+	client := &mockRESTClient{postFunc: mockPostError("forbidden")}
+	_, err := runPreviewDeploy(client, previewDeployCmdFlags{deploy: deployCmdFlags{dir: tmp, app: "my-app"}, pr: 3})
+	require.ErrorContains(t, err, "error deploying app")
+}
+
+func previewCleanupClient(deleted *[]string) *mockRESTClient {
+	return &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			switch path {
+			case "runtime/my-app/deployment/revisions":
+				return json.Unmarshal([]byte(previewRevisionsJSON), resp)
+			case "repos/octo/site/pulls/1":
+				return json.Unmarshal([]byte(`{"state":"closed"}`), resp)
+			case "repos/octo/site/pulls/2":
+				return json.Unmarshal([]byte(`{"state":"open"}`), resp)
+			}
+			return nil
+		},
+		deleteFunc: func(path string, resp interface{}) error {
+			*deleted = append(*deleted, path)
+			return nil
+		},
+	}
+}
+
+func TestRunPreviewCleanup(t *testing.T) {
+	var deleted []string
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	stale, err := runPreviewCleanup(previewCleanupClient(&deleted), previewCleanupCmdFlags{app: "my-app", repo: "octo/site", ttl: defaultPreviewTTL}, now)
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, rev := range stale {
+		names = append(names, rev.Name)
+	}
+	assert.ElementsMatch(t, []string{"preview-pr-1", "preview-old-branch"}, names)
+	assert.ElementsMatch(t, []string{
+		"runtime/my-app/deployment?revision_name=preview-pr-1",
+		"runtime/my-app/deployment?revision_name=preview-old-branch",
+	}, deleted)
+}
+
+func TestRunPreviewCleanup_DryRun(t *testing.T) {
+	var deleted []string
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	stale, err := runPreviewCleanup(previewCleanupClient(&deleted), previewCleanupCmdFlags{app: "my-app", repo: "octo/site", ttl: defaultPreviewTTL, dryRun: true}, now)
+	require.NoError(t, err)
+	assert.Len(t, stale, 2)
+	assert.Empty(t, deleted)
+}
+
+func TestRunPreviewCleanup_NoTTL(t *testing.T) {
+	var deleted []string
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	stale, err := runPreviewCleanup(previewCleanupClient(&deleted), previewCleanupCmdFlags{app: "my-app", repo: "octo/site"}, now)
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+	assert.Equal(t, "preview-pr-1", stale[0].Name)
+}
+
+func TestRunPreviewCleanup_PullRequestLookupFails(t *testing.T) {
+	var deleted []string
+	client := previewCleanupClient(&deleted)
+	client.getFunc = func(path string, resp interface{}) error {
+		if strings.HasPrefix(path, "repos/") {
+			return mockGetError("not found")(path, resp)
+		}
+		return json.Unmarshal([]byte(previewRevisionsJSON), resp)
+	}
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	stale, err := runPreviewCleanup(client, previewCleanupCmdFlags{app: "my-app", repo: "octo/site", ttl: defaultPreviewTTL}, now)
+	require.NoError(t, err)
+	require.Len(t, stale, 1, "previews should still be cleaned up by age")
+	assert.Equal(t, "preview-old-branch", stale[0].Name)
+}
+
+func TestRunPreviewCleanup_InvalidRepo(t *testing.T) {
+	var deleted []string
+	_, err := runPreviewCleanup(previewCleanupClient(&deleted), previewCleanupCmdFlags{app: "my-app", repo: "not a repo"}, time.Now())
+	require.ErrorContains(t, err, "invalid repository")
+}
+
+func TestRunPreviewCleanup_NoAppName(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	_, err = runPreviewCleanup(&mockRESTClient{}, previewCleanupCmdFlags{}, time.Now())
+	require.Error(t, err)
+}
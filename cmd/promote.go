@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+type promoteCmdFlags struct {
+	app          string
+	config       string
+	fromApp      string
+	toApp        string
+	fromRevision string
+	toRevision   string
+	sha          string
+}
+
+type promoteReq struct {
+	SourceApp          string `json:"source_app"`
+	SourceRevisionName string `json:"source_revision_name,omitempty"`
+	RevisionName       string `json:"revision_name,omitempty"`
+	Revision           string `json:"revision,omitempty"`
+}
+
+type promoteResp struct {
+	AppUrl string `json:"app_url"`
+}
+
+// promoteResult describes a completed promotion.
+type promoteResult struct {
+	fromApp string
+	toApp   string
+	source  revision
+	appUrl  string
+}
+
+func init() {
+	promoteCmdFlags := promoteCmdFlags{}
+	promoteCmd := &cobra.Command{
+		Use:   "promote",
+		Short: "Promote a revision to another revision name or app",
+		Long: heredoc.Doc(`
+			Promote a deployed revision to another revision name, or to another app, by reusing the
+			exact bundle that was deployed to the source revision instead of uploading it again.
+			Use --sha to refuse the promotion unless the source revision was deployed from that SHA.
+			The source app defaults to the app given by --app, --config or runtime.config.json in the
+			current directory, and the target app defaults to the source app.
+		`),
+		Example: heredoc.Doc(`
+			$ gh runtime promote --from-revision staging --to-revision prod
+			# => Promotes the 'staging' revision to 'prod'
+
+			$ gh runtime promote --from-app my-app-staging --to-app my-app --from-revision main
+			# => Promotes the 'main' revision of 'my-app-staging' to the default revision of 'my-app'
+
+			$ gh runtime promote --from-revision staging --to-revision prod --sha 1a2b3c4
+			# => Promotes 'staging' only if it was deployed from SHA '1a2b3c4'
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newRESTClient()
+			if err != nil {
+				return fmt.Errorf("failed creating REST client: %v", err)
+			}
+
+			result, err := runPromote(client, promoteCmdFlags)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Promoted '%s' (%s) of app '%s' to '%s' of app '%s'\n",
+				displayRevisionName(promoteCmdFlags.fromRevision), shortSHA(result.source.SHA), result.fromApp,
+				displayRevisionName(promoteCmdFlags.toRevision), result.toApp)
+			if result.appUrl != "" {
+				fmt.Printf("%s\n", result.appUrl)
+			}
+			return nil
+		},
+	}
+
+	promoteCmd.Flags().StringVarP(&promoteCmdFlags.app, "app", "a", "", "The app ID to promote within")
+	promoteCmd.Flags().StringVarP(&promoteCmdFlags.config, "config", "c", "", "Path to runtime config file")
+	promoteCmd.Flags().StringVar(&promoteCmdFlags.fromApp, "from-app", "", "The app ID to promote from")
+	promoteCmd.Flags().StringVar(&promoteCmdFlags.toApp, "to-app", "", "The app ID to promote to")
+	promoteCmd.Flags().StringVar(&promoteCmdFlags.fromRevision, "from-revision", "", "The revision name to promote from")
+	promoteCmd.Flags().StringVar(&promoteCmdFlags.toRevision, "to-revision", "", "The revision name to promote to")
+	promoteCmd.Flags().StringVarP(&promoteCmdFlags.sha, "sha", "s", "", "Refuse to promote unless the source revision was deployed from this SHA")
+	rootCmd.AddCommand(promoteCmd)
+}
+
+func runPromote(client restClient, flags promoteCmdFlags) (promoteResult, error) {
+	fromApp := flags.fromApp
+	if fromApp == "" {
+		var err error
+		fromApp, err = config.ResolveAppName(flags.app, flags.config)
+		if err != nil {
+			return promoteResult{}, fmt.Errorf("%v (or use --from-app)", err)
+		}
+	}
+
+	toApp := flags.toApp
+	if toApp == "" {
+		toApp = fromApp
+	}
+
+	if fromApp == toApp && flags.fromRevision == flags.toRevision {
+		return promoteResult{}, fmt.Errorf("the source and target are the same: use --to-revision or --to-app to choose a different target")
+	}
+
+	source, err := getRevision(client, fromApp, flags.fromRevision)
+	if err != nil {
+		return promoteResult{}, err
+	}
+
+	if flags.sha != "" && !shaMatches(source.SHA, flags.sha) {
+		return promoteResult{}, fmt.Errorf("refusing to promote: revision '%s' of app '%s' was deployed from SHA '%s', not '%s'",
+			displayRevisionName(flags.fromRevision), fromApp, source.SHA, flags.sha)
+	}
+
+	body, err := json.Marshal(promoteReq{
+		SourceApp:          fromApp,
+		SourceRevisionName: flags.fromRevision,
+		RevisionName:       flags.toRevision,
+		Revision:           source.SHA,
+	})
+	if err != nil {
+		return promoteResult{}, fmt.Errorf("error marshalling request body: %v", err)
+	}
+
+	response := promoteResp{}
+	err = client.Post(fmt.Sprintf("runtime/%s/deployment/promote", toApp), bytes.NewReader(body), &response)
+	if err != nil {
+		return promoteResult{}, fmt.Errorf("error promoting revision: %w", describeAPIError(err))
+	}
+
+	return promoteResult{fromApp: fromApp, toApp: toApp, source: source, appUrl: response.AppUrl}, nil
+}
+
+// shaMatches reports whether expected, which may be abbreviated, identifies
+// the full SHA actual.
+func shaMatches(actual, expected string) bool {
+	if actual == "" || len(expected) < 4 {
+		return false
+	}
+	return strings.HasPrefix(strings.ToLower(actual), strings.ToLower(expected))
+}
+
+// displayRevisionName returns the name used for a revision in messages.
+func displayRevisionName(name string) string {
+	if name == "" {
+		return "(default)"
+	}
+	return name
+}
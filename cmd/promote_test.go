@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPromote_Success(t *testing.T) {
+	var getPath, postPath string
+	var capturedBody promoteReq
+	client := &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			getPath = path
+			return json.Unmarshal([]byte(`{"revision":"1a2b3c4d5e","status":"inactive"}`), resp)
+		},
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			postPath = path
+			require.NoError(t, json.NewDecoder(body).Decode(&capturedBody))
+			return json.Unmarshal([]byte(`{"app_url":"https://prod.example.com"}`), resp)
+		},
+	}
+
+	result, err := runPromote(client, promoteCmdFlags{app: "my-app", fromRevision: "staging", toRevision: "prod", sha: "1A2B3C4"})
+	require.NoError(t, err)
+	assert.Equal(t, "runtime/my-app/deployment?revision_name=staging", getPath)
+	assert.Equal(t, "runtime/my-app/deployment/promote", postPath)
+	assert.Equal(t, promoteReq{SourceApp: "my-app", SourceRevisionName: "staging", RevisionName: "prod", Revision: "1a2b3c4d5e"}, capturedBody)
+	assert.Equal(t, "https://prod.example.com", result.appUrl)
+	assert.Equal(t, "staging", result.source.Name)
+}
+
+func TestRunPromote_AcrossApps(t *testing.T) {
+	var getPath, postPath string
+	client := &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			getPath = path
+			return json.Unmarshal([]byte(`{"revision":"abc123"}`), resp)
+		},
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			postPath = path
+			return nil
+		},
+	}
+
+	result, err := runPromote(client, promoteCmdFlags{fromApp: "app-staging", toApp: "app-prod", fromRevision: "main"})
+	require.NoError(t, err)
+	assert.Equal(t, "runtime/app-staging/deployment?revision_name=main", getPath)
+	assert.Equal(t, "runtime/app-prod/deployment/promote", postPath)
+	assert.Equal(t, "app-prod", result.toApp)
+}
+
+func TestRunPromote_SHAMismatch(t *testing.T) {
+	posted := false
+	client := &mockRESTClient{
+		getFunc: mockGetResponse(`{"revision":"abc123"}`),
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			posted = true
+			return nil
+		},
+	}
+
+	_, err := runPromote(client, promoteCmdFlags{app: "my-app", fromRevision: "staging", toRevision: "prod", sha: "def456"})
+	require.ErrorContains(t, err, "refusing to promote")
+	assert.False(t, posted)
+}
+
+func TestRunPromote_SameSourceAndTarget(t *testing.T) {
+	_, err := runPromote(&mockRESTClient{}, promoteCmdFlags{app: "my-app", fromRevision: "staging", toRevision: "staging"})
+	require.ErrorContains(t, err, "the source and target are the same")
+}
+
+func TestRunPromote_APIError(t *testing.T) {
+	client := &mockRESTClient{
+		getFunc:  mockGetResponse(`{"revision":"abc123"}`),
+		postFunc: mockPostError("conflict"),
+	}
+
+	_, err := runPromote(client, promoteCmdFlags{app: "my-app", fromRevision: "staging", toRevision: "prod"})
+	require.ErrorContains(t, err, "error promoting revision")
+}
+
+func TestShaMatches(t *testing.T) {
+	assert.True(t, shaMatches("1a2b3c4d5e", "1a2b3c4d5e"))
+	assert.True(t, shaMatches("1a2b3c4d5e", "1A2B3C"))
+	assert.False(t, shaMatches("1a2b3c4d5e", "1a2"))
+	assert.False(t, shaMatches("1a2b3c4d5e", "ffff"))
+	assert.False(t, shaMatches("", "1a2b3c4"))
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/github/gh-runtime-cli/internal/provenance"
+)
+
+// signingKeyEnv holds the PEM encoded ed25519 private key that provenance is
+// signed with when --signing-key is not given.
+const signingKeyEnv = "GH_RUNTIME_SIGNING_KEY"
+
+// provenanceExt is appended to the name of a packed bundle to name the file
+// holding its signed provenance.
+const provenanceExt = ".intoto.jsonl"
+
+// loadSigningKey returns the key to sign provenance with, read from keyPath or
+// from GH_RUNTIME_SIGNING_KEY. It returns nil if neither is set, in which case
+// no provenance is produced.
+func loadSigningKey(keyPath string) (ed25519.PrivateKey, error) {
+	var data []byte
+	if keyPath != "" {
+		var err error
+		data, err = os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading signing key '%s': %v", keyPath, err)
+		}
+	} else if value := os.Getenv(signingKeyEnv); value != "" {
+		data = []byte(value)
+	} else {
+		return nil, nil
+	}
+
+	key, err := provenance.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %v", err)
+	}
+	return key, nil
+}
+
+// loadPublicKey reads the key provenance is verified with.
+func loadPublicKey(keyPath string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading public key '%s': %v", keyPath, err)
+	}
+	key, err := provenance.ParsePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key '%s': %v", keyPath, err)
+	}
+	return key, nil
+}
+
+// signProvenance describes how the bundle at bundlePath was built and signs
+// the statement with the deployment's signing key.
+func (d deployment) signProvenance(bundlePath, bundleName string, startedOn, finishedOn time.Time) (provenance.Envelope, error) {
+	digest, err := provenance.FileDigest(bundlePath)
+	if err != nil {
+		return provenance.Envelope{}, fmt.Errorf("error creating provenance: %v", err)
+	}
+
+	parameters := map[string]string{}
+	addParameter := func(name, value string) {
+		if value != "" {
+			parameters[name] = value
+		}
+	}
+	addParameter("app", d.appName)
+	addParameter("revisionName", d.flags.revisionName)
+	addParameter("archive", d.flags.archive)
+	addParameter("dir", d.flags.dir)
+	if !d.flags.skipBuild {
+		addParameter("buildCommand", d.flags.buildCmd)
+	}
+
+	builder, invocationID, repository := provenanceBuilder()
+	statement := provenance.NewStatement(provenance.Build{
+		BundleName:   bundleName,
+		BundleDigest: digest,
+		Commit:       d.flags.sha,
+		Repository:   repository,
+		Parameters:   parameters,
+		Builder:      builder,
+		InvocationID: invocationID,
+		StartedOn:    startedOn,
+		FinishedOn:   finishedOn,
+	})
+
+	envelope, err := provenance.Sign(statement, d.signingKey)
+	if err != nil {
+		return provenance.Envelope{}, fmt.Errorf("error signing provenance: %v", err)
+	}
+	return envelope, nil
+}
+
+// provenanceBuilder identifies what is running the build. In GitHub Actions
+// this is the workflow and its run, otherwise it is this CLI.
+func provenanceBuilder() (builder, invocationID, repository string) {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		return "https://github.com/github/gh-runtime-cli@v" + Version, "", ""
+	}
+
+	server := strings.TrimSuffix(os.Getenv("GITHUB_SERVER_URL"), "/")
+	if server == "" {
+		server = "https://github.com"
+	}
+	repository = server + "/" + os.Getenv("GITHUB_REPOSITORY")
+	builder = server + "/" + os.Getenv("GITHUB_WORKFLOW_REF")
+	if runID := os.Getenv("GITHUB_RUN_ID"); runID != "" {
+		invocationID = repository + "/actions/runs/" + runID
+		if attempt := os.Getenv("GITHUB_RUN_ATTEMPT"); attempt != "" {
+			invocationID += "/attempts/" + attempt
+		}
+	}
+	return builder, invocationID, repository
+}
+
+// uploadProvenance attaches signed provenance to the deployed bundle.
+func (d deployment) uploadProvenance(client restClient, envelope provenance.Envelope) error {
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("error encoding provenance: %v", err)
+	}
+
+	err = client.Post(deploymentURL(d.appName, "provenance", revisionQuery(d.flags.revisionName, d.flags.sha)), bytes.NewReader(body), nil)
+	if err != nil {
+		return fmt.Errorf("error uploading provenance: %w", describeAPIError(err))
+	}
+	return nil
+}
+
+// writeProvenance writes a signed provenance envelope as a single JSON line.
+func writeProvenance(path string, envelope provenance.Envelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("error encoding provenance: %v", err)
+	}
+	err = os.WriteFile(path, append(data, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing provenance '%s': %v", path, err)
+	}
+	return nil
+}
+
+// readProvenance reads a provenance envelope written by writeProvenance. Only
+// the first envelope of a JSON lines file is read.
+func readProvenance(path string) (provenance.Envelope, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return provenance.Envelope{}, fmt.Errorf("error reading provenance '%s': %v", path, err)
+	}
+
+	envelope := provenance.Envelope{}
+	err = json.NewDecoder(bytes.NewReader(data)).Decode(&envelope)
+	if err != nil {
+		return provenance.Envelope{}, fmt.Errorf("error parsing provenance '%s': %v", path, err)
+	}
+	return envelope, nil
+}
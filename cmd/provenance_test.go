@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-runtime-cli/internal/provenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestKeys writes a new ed25519 key pair as PEM files and returns their
+// paths along with the public key.
+func writeTestKeys(t *testing.T) (string, string, ed25519.PublicKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "signing.pem")
+	publicPath := filepath.Join(dir, "signing.pub")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600))
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0644))
+	return privatePath, publicPath, public
+}
+
+func TestRunPack_SignedProvenance(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	privatePath, publicPath, public := writeTestKeys(t)
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{"index.html": "<html></html>"})
+	out := filepath.Join(tmp, "bundle.zip")
+
+	err := runPack(packCmdFlags{bundle: deployCmdFlags{dir: src, buildCmd: "true", skipBuild: true, sha: "abc123", signingKey: privatePath}, out: out})
+	require.NoError(t, err)
+
+	result, err := runVerify(out, verifyCmdFlags{provenance: out + provenanceExt, publicKey: publicPath})
+	require.NoError(t, err)
+	assert.Empty(t, result.Problems)
+	require.NotNil(t, result.Provenance)
+	assert.Equal(t, "abc123", result.Provenance.Commit)
+	assert.Equal(t, "https://github.com/github/gh-runtime-cli@v"+Version, result.Provenance.Builder)
+
+	envelope, err := readProvenance(out + provenanceExt)
+	require.NoError(t, err)
+	statement, err := provenance.Verify(envelope, public)
+	require.NoError(t, err)
+	assert.Equal(t, "bundle.zip", statement.Subject[0].Name)
+	assert.NotContains(t, statement.Predicate.BuildDefinition.ExternalParameters, "buildCommand", "skipped builds should not be recorded")
+}
+
+func TestRunVerify_ProvenanceMismatch(t *testing.T) {
+	privatePath, publicPath, _ := writeTestKeys(t)
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{"index.html": "<html></html>"})
+	out := filepath.Join(tmp, "bundle.zip")
+	require.NoError(t, runPack(packCmdFlags{bundle: deployCmdFlags{dir: src, signingKey: privatePath}, out: out}))
+
+	// Replace the bundle with a different one, keeping the provenance.
+	writeTestTree(t, src, map[string]string{"index.html": "<html>changed</html>"})
+	other := filepath.Join(tmp, "other.zip")
+	require.NoError(t, runPack(packCmdFlags{bundle: deployCmdFlags{dir: src}, out: other}))
+	require.NoError(t, os.Rename(other, out))
+
+	result, err := runVerify(out, verifyCmdFlags{provenance: out + provenanceExt, publicKey: publicPath})
+	require.NoError(t, err)
+	require.Len(t, result.Problems, 1)
+	assert.Contains(t, result.Problems[0], "does not match the provenance")
+	assert.Nil(t, result.Provenance)
+}
+
+func TestRunVerify_ProvenanceWrongKey(t *testing.T) {
+	privatePath, _, _ := writeTestKeys(t)
+	_, otherPublicPath, _ := writeTestKeys(t)
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, map[string]string{"index.html": "<html></html>"})
+	out := filepath.Join(tmp, "bundle.zip")
+	require.NoError(t, runPack(packCmdFlags{bundle: deployCmdFlags{dir: src, signingKey: privatePath}, out: out}))
+
+	result, err := runVerify(out, verifyCmdFlags{provenance: out + provenanceExt, publicKey: otherPublicPath})
+	require.NoError(t, err)
+	require.Len(t, result.Problems, 1)
+	assert.Contains(t, result.Problems[0], "provenance signature is invalid")
+}
+
+func TestRunVerify_ProvenanceRequiresPublicKey(t *testing.T) {
+	_, err := runVerify("bundle.zip", verifyCmdFlags{provenance: "bundle.zip" + provenanceExt})
+	require.ErrorContains(t, err, "--provenance and --public-key must be used together")
+}
+
+func TestRunDeploy_UploadsProvenance(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "octo/site")
+	t.Setenv("GITHUB_WORKFLOW_REF", "octo/site/.github/workflows/deploy.yml@refs/heads/main")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	t.Setenv("GITHUB_RUN_ATTEMPT", "1")
+	privatePath, _, public := writeTestKeys(t)
+	key, err := os.ReadFile(privatePath)
+	require.NoError(t, err)
+	t.Setenv(signingKeyEnv, string(key))
+
+	src := t.TempDir()
+	writeTestTree(t, src, map[string]string{"index.html": "<html></html>"})
+
+	posts := map[string][]byte{}
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			data, err := io.ReadAll(body)
+			posts[path] = data
+			return err
+		},
+	}
+
+	err = runDeploy(client, deployCmdFlags{dir: src, app: "my-app", revisionName: "v2", sha: "abc123"})
+	require.NoError(t, err)
+
+	bundle := posts["runtime/my-app/deployment/bundle?revision=abc123&revision_name=v2"]
+	require.NotEmpty(t, bundle)
+	envelopeJSON := posts["runtime/my-app/deployment/provenance?revision=abc123&revision_name=v2"]
+	require.NotEmpty(t, envelopeJSON)
+
+	var envelope provenance.Envelope
+	require.NoError(t, json.Unmarshal(envelopeJSON, &envelope))
+	statement, err := provenance.Verify(envelope, public)
+	require.NoError(t, err)
+
+	digest := sha256.Sum256(bundle)
+	assert.True(t, statement.CoversDigest(hex.EncodeToString(digest[:])))
+	assert.Equal(t, "abc123", statement.Commit())
+	assert.Equal(t, "https://github.com/octo/site/.github/workflows/deploy.yml@refs/heads/main", statement.Predicate.RunDetails.Builder.ID)
+	assert.Equal(t, "https://github.com/octo/site/actions/runs/42/attempts/1", statement.Predicate.RunDetails.Metadata.InvocationID)
+	assert.Equal(t, "v2", statement.Predicate.BuildDefinition.ExternalParameters["revisionName"])
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	t.Setenv(signingKeyEnv, "")
+	key, err := loadSigningKey("")
+	require.NoError(t, err)
+	assert.Nil(t, key)
+
+	t.Setenv(signingKeyEnv, "not a key")
+	_, err = loadSigningKey("")
+	require.ErrorContains(t, err, "invalid signing key")
+
+	_, err = loadSigningKey(filepath.Join(t.TempDir(), "missing.pem"))
+	require.ErrorContains(t, err, "error reading signing key")
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/github/gh-runtime-cli/internal/git"
+)
+
+// runDeployRef exports the tree of a git ref from the repository of the
+// current directory into a temporary directory, then builds and deploys it
+// from there with the SHA of the ref.
+func runDeployRef(client restClient, flags deployCmdFlags) error {
+	if flags.archive != "" {
+		return fmt.Errorf("--archive cannot be used with --ref")
+	}
+	if flags.sha != "" {
+		return fmt.Errorf("--sha cannot be used with --ref, the SHA of the ref is deployed")
+	}
+
+	sha, err := git.RevParse(".", flags.ref)
+	if err != nil {
+		return fmt.Errorf("error resolving ref '%s': %v", flags.ref, err)
+	}
+	prefix, err := git.Prefix(".")
+	if err != nil {
+		return fmt.Errorf("error resolving ref '%s': %v", flags.ref, err)
+	}
+
+	exportDir, err := os.MkdirTemp("", "gh-runtime-ref-*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(exportDir)
+
+	err = git.Export(".", sha, exportDir)
+	if err != nil {
+		return fmt.Errorf("error exporting ref '%s': %v", flags.ref, err)
+	}
+	fmt.Printf("Exported '%s' (%s) to a temporary directory\n", flags.ref, shortSHA(sha))
+
+	flags.sha = sha
+	d, err := resolveDeploymentIn(filepath.Join(exportDir, filepath.FromSlash(prefix)), flags)
+	if err != nil {
+		return err
+	}
+	if flags.gitMetadata {
+		// The exported tree is not a repository, so read the commit from the
+		// current one.
+		d.commit.message, _ = git.CommitSubject(".", sha)
+	}
+
+	return d.run(client)
+}
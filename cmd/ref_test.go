@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-runtime-cli/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturingPostClient records the path and the files of the bundle posted by a
+// deploy, leaving out the bundle manifest.
+func capturingPostClient(t *testing.T, path *string, files *map[string]string) *mockRESTClient {
+	return &mockRESTClient{
+		postFunc: func(p string, body io.Reader, resp interface{}) error {
+			*path = p
+			data, err := io.ReadAll(body)
+			require.NoError(t, err)
+			reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			require.NoError(t, err)
+			*files = map[string]string{}
+			for _, f := range reader.File {
+				if !f.FileInfo().IsDir() && f.Name != bundleManifestPath {
+					(*files)[f.Name] = readZipEntry(t, f)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func TestRunDeployRef(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{
+		"runtime.config.json": `{"app":"my-app","dir":"dist"}`,
+		"dist/index.html":     "released",
+	})
+	sha, err := git.HeadSHA(repo)
+	require.NoError(t, err)
+	_, err = git.Run(repo, "tag", "v1.0.0")
+	require.NoError(t, err)
+
+	// Local changes must not end up in the deploy.
+	writeTestTree(t, repo, map[string]string{"dist/index.html": "work in progress", "dist/draft.html": "draft"})
+
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(repo))
+	defer os.Chdir(origDir)
+
+	var postPath string
+	var files map[string]string
+	err = runDeployRef(capturingPostClient(t, &postPath, &files), deployCmdFlags{ref: "v1.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, "runtime/my-app/deployment/bundle?revision="+sha, postPath)
+	assert.Equal(t, map[string]string{"index.html": "released"}, files)
+}
+
+func TestRunDeployRef_BuildsInExportedTree(t *testing.T) {
+	skipWithoutShell(t)
+	repo := initTestRepo(t, map[string]string{
+		"site/runtime.config.json": `{"app":"my-app","dir":"out","build":"mkdir -p out && cp src.html out/index.html"}`,
+		"site/src.html":            "built",
+	})
+
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(filepath.Join(repo, "site")))
+	defer os.Chdir(origDir)
+
+	var postPath string
+	var files map[string]string
+	err = runDeployRef(capturingPostClient(t, &postPath, &files), deployCmdFlags{ref: "HEAD"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"index.html": "built"}, files)
+	_, err = os.Stat(filepath.Join(repo, "site", "out"))
+	assert.True(t, os.IsNotExist(err), "the build should not run in the working tree")
+}
+
+func TestRunDeployRef_UnknownRef(t *testing.T) {
+	repo := initTestRepo(t, map[string]string{"index.html": "hi"})
+
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(repo))
+	defer os.Chdir(origDir)
+
+	err = runDeployRef(&mockRESTClient{}, deployCmdFlags{ref: "does-not-exist", app: "my-app", dir: "."})
+	require.ErrorContains(t, err, "error resolving ref 'does-not-exist'")
+}
+
+func TestRunDeployRef_WithSha(t *testing.T) {
+	err := runDeployRef(&mockRESTClient{}, deployCmdFlags{ref: "HEAD", sha: "abc123"})
+	require.ErrorContains(t, err, "--sha cannot be used with --ref")
+}
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/cli/go-gh/v2/pkg/text"
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+type revisionCmdFlags struct {
+	app    string
+	config string
+	json   bool
+}
+
+// revision is a deployed revision of an app.
+type revision struct {
+	Name      string    `json:"revision_name"`
+	SHA       string    `json:"revision,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Size      int64     `json:"size"`
+	Status    string    `json:"status"`
+	AppUrl    string    `json:"app_url"`
+}
+
+// Revision statuses reported by the API.
+const (
+	// revisionStatusActive is the status of the revision the app is serving.
+	revisionStatusActive = "active"
+	// revisionStatusFailed is the status of a revision that could not be deployed.
+	revisionStatusFailed = "failed"
+)
+
+func init() {
+	revisionCmd := &cobra.Command{
+		Use:   "revision",
+		Short: "Manage the revisions of a GitHub Runtime app",
+		Long: heredoc.Doc(`
+			List, inspect and delete the revisions of a GitHub Runtime app.
+			You can specify the app ID using --app flag, --config flag to read from a runtime config file,
+			or it will automatically read from runtime.config.json in the current directory if it exists.
+		`),
+	}
+
+	listFlags := revisionCmdFlags{}
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the revisions of an app",
+		Example: heredoc.Doc(`
+			$ gh runtime revision list --app my-app
+			# => Lists the revisions of the app with ID 'my-app'
+
+			$ gh runtime revision list --json
+			# => Lists the revisions of the app from runtime.config.json as JSON
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newRESTClient()
+			if err != nil {
+				return fmt.Errorf("failed creating REST client: %v", err)
+			}
+
+			revisions, err := runRevisionList(client, listFlags)
+			if err != nil {
+				return err
+			}
+
+			if listFlags.json {
+				return printJSON(os.Stdout, revisions)
+			}
+			t := term.FromEnv()
+			width, _, err := t.Size()
+			if err != nil {
+				width = 80
+			}
+			return printRevisions(os.Stdout, t.IsTerminalOutput(), width, revisions)
+		},
+	}
+	addRevisionFlags(listCmd, &listFlags)
+
+	getFlags := revisionCmdFlags{}
+	getCmd := &cobra.Command{
+		Use:   "get NAME",
+		Short: "Get details of a revision",
+		Example: heredoc.Doc(`
+			$ gh runtime revision get staging --app my-app
+			# => Retrieves details of the 'staging' revision of the app with ID 'my-app'
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newRESTClient()
+			if err != nil {
+				return fmt.Errorf("failed creating REST client: %v", err)
+			}
+
+			rev, err := runRevisionGet(client, getFlags, args[0])
+			if err != nil {
+				return err
+			}
+
+			if getFlags.json {
+				return printJSON(os.Stdout, rev)
+			}
+			printRevision(os.Stdout, rev)
+			return nil
+		},
+	}
+	addRevisionFlags(getCmd, &getFlags)
+
+	deleteFlags := revisionCmdFlags{}
+	deleteCmd := &cobra.Command{
+		Use:   "delete NAME",
+		Short: "Delete a revision",
+		Example: heredoc.Doc(`
+			$ gh runtime revision delete staging --app my-app
+			# => Deletes the 'staging' revision of the app with ID 'my-app'
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newRESTClient()
+			if err != nil {
+				return fmt.Errorf("failed creating REST client: %v", err)
+			}
+
+			appName, err := runRevisionDelete(client, deleteFlags, args[0])
+			if err != nil {
+				return err
+			}
+
+			if deleteFlags.json {
+				return printJSON(os.Stdout, map[string]string{"app": appName, "revision_name": args[0]})
+			}
+			fmt.Printf("Revision '%s' of app '%s' deleted\n", args[0], appName)
+			return nil
+		},
+	}
+	addRevisionFlags(deleteCmd, &deleteFlags)
+
+	revisionCmd.AddCommand(listCmd, getCmd, deleteCmd)
+	rootCmd.AddCommand(revisionCmd)
+}
+
+func addRevisionFlags(cmd *cobra.Command, flags *revisionCmdFlags) {
+	cmd.Flags().StringVarP(&flags.app, "app", "a", "", "The app ID")
+	cmd.Flags().StringVarP(&flags.config, "config", "c", "", "Path to runtime config file")
+	cmd.Flags().BoolVar(&flags.json, "json", false, "Output JSON")
+}
+
+func runRevisionList(client restClient, flags revisionCmdFlags) ([]revision, error) {
+	appName, err := config.ResolveAppName(flags.app, flags.config)
+	if err != nil {
+		return nil, err
+	}
+
+	return listRevisions(client, appName)
+}
+
+// listRevisions returns the revisions of an app, newest first.
+func listRevisions(client restClient, appName string) ([]revision, error) {
+	revisions := []revision{}
+	err := client.Get(fmt.Sprintf("runtime/%s/deployment/revisions", appName), &revisions)
+	if err != nil {
+		return nil, fmt.Errorf("error listing revisions: %w", describeAPIError(err))
+	}
+
+	sortRevisionsNewestFirst(revisions)
+	return revisions, nil
+}
+
+func runRevisionGet(client restClient, flags revisionCmdFlags, name string) (revision, error) {
+	appName, err := config.ResolveAppName(flags.app, flags.config)
+	if err != nil {
+		return revision{}, err
+	}
+
+	return getRevision(client, appName, name)
+}
+
+// getRevision returns a single revision of an app.
+func getRevision(client restClient, appName, name string) (revision, error) {
+	rev := revision{}
+	err := client.Get(deploymentURL(appName, "", revisionQuery(name, "")), &rev)
+	if err != nil {
+		return revision{}, fmt.Errorf("error retrieving revision '%s': %w", name, describeAPIError(err))
+	}
+	if rev.Name == "" {
+		rev.Name = name
+	}
+
+	return rev, nil
+}
+
+func runRevisionDelete(client restClient, flags revisionCmdFlags, name string) (string, error) {
+	appName, err := config.ResolveAppName(flags.app, flags.config)
+	if err != nil {
+		return "", err
+	}
+
+	err = deleteRevision(client, appName, name)
+	if err != nil {
+		return "", err
+	}
+
+	return appName, nil
+}
+
+// deleteRevision deletes a single revision of an app.
+func deleteRevision(client restClient, appName, name string) error {
+	var response string
+	err := client.Delete(deploymentURL(appName, "", revisionQuery(name, "")), &response)
+	if err != nil {
+		return fmt.Errorf("error deleting revision '%s': %w", name, describeAPIError(err))
+	}
+
+	return nil
+}
+
+func sortRevisionsNewestFirst(revisions []revision) {
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].CreatedAt.After(revisions[j].CreatedAt)
+	})
+}
+
+// shortSHA abbreviates a commit SHA for display.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// printRevisions prints revisions as a table. Terminal output is aligned and
+// uses relative times, while other output is tab-separated for scripts.
+func printRevisions(w io.Writer, isTTY bool, width int, revisions []revision) error {
+	now := time.Now()
+	table := tableprinter.New(w, isTTY, width)
+	table.AddHeader([]string{"NAME", "SHA", "CREATED", "SIZE", "STATUS", "URL"})
+	for _, rev := range revisions {
+		table.AddField(rev.Name)
+		if isTTY {
+			table.AddField(shortSHA(rev.SHA))
+			table.AddField(text.RelativeTimeAgo(now, rev.CreatedAt))
+			table.AddField(formatByteSize(rev.Size))
+		} else {
+			table.AddField(rev.SHA)
+			table.AddField(rev.CreatedAt.Format(time.RFC3339))
+			table.AddField(fmt.Sprintf("%d", rev.Size))
+		}
+		table.AddField(rev.Status)
+		table.AddField(rev.AppUrl)
+		table.EndRow()
+	}
+
+	return table.Render()
+}
+
+// printRevision prints the details of a single revision.
+func printRevision(w io.Writer, rev revision) {
+	fmt.Fprintf(w, "Name:    %s\n", rev.Name)
+	fmt.Fprintf(w, "SHA:     %s\n", rev.SHA)
+	fmt.Fprintf(w, "Created: %s\n", rev.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(w, "Size:    %s\n", formatByteSize(rev.Size))
+	fmt.Fprintf(w, "Status:  %s\n", rev.Status)
+	fmt.Fprintf(w, "URL:     %s\n", rev.AppUrl)
+}
+
+// printJSON writes v as indented JSON.
+func printJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling JSON: %v", err)
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const revisionsJSON = `[
+	{"revision_name":"staging","revision":"1111111111111111111111111111111111111111","created_at":"2024-05-01T10:00:00Z","size":2048,"status":"inactive","app_url":"https://staging.example.com"},
+	{"revision_name":"prod","revision":"2222222222222222222222222222222222222222","created_at":"2024-05-02T10:00:00Z","size":4096,"status":"active","app_url":"https://prod.example.com"}
+]`
+
+func TestRunRevisionList(t *testing.T) {
+	var capturedPath string
+	client := &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			capturedPath = path
+			return json.Unmarshal([]byte(revisionsJSON), resp)
+		},
+	}
+
+	revisions, err := runRevisionList(client, revisionCmdFlags{app: "my-app"})
+	require.NoError(t, err)
+	assert.Equal(t, "runtime/my-app/deployment/revisions", capturedPath)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "prod", revisions[0].Name, "revisions should be sorted newest first")
+	assert.Equal(t, "staging", revisions[1].Name)
+}
+
+func TestRunRevisionList_NoAppName(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	_, err = runRevisionList(&mockRESTClient{}, revisionCmdFlags{})
+	require.ErrorContains(t, err, "--app flag is required")
+}
+
+func TestRunRevisionList_APIError(t *testing.T) {
+	client := &mockRESTClient{getFunc: mockGetError("server error")}
+
+	_, err := runRevisionList(client, revisionCmdFlags{app: "my-app"})
+	require.ErrorContains(t, err, "error listing revisions")
+}
+
+func TestRunRevisionGet(t *testing.T) {
+	var capturedPath string
+	client := &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			capturedPath = path
+			return json.Unmarshal([]byte(`{"revision":"abc","status":"active","app_url":"https://staging.example.com"}`), resp)
+		},
+	}
+
+	rev, err := runRevisionGet(client, revisionCmdFlags{app: "my-app"}, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, "runtime/my-app/deployment?revision_name=staging", capturedPath)
+	assert.Equal(t, "staging", rev.Name)
+	assert.Equal(t, "abc", rev.SHA)
+}
+
+func TestRunRevisionDelete(t *testing.T) {
+	var capturedPath string
+	client := &mockRESTClient{
+		deleteFunc: func(path string, resp interface{}) error {
+			capturedPath = path
+			return nil
+		},
+	}
+
+	appName, err := runRevisionDelete(client, revisionCmdFlags{app: "my-app"}, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, "my-app", appName)
+	assert.Equal(t, "runtime/my-app/deployment?revision_name=staging", capturedPath)
+}
+
+func TestRunRevisionDelete_APIError(t *testing.T) {
+	client := &mockRESTClient{deleteFunc: mockDeleteError("not found")}
+
+	_, err := runRevisionDelete(client, revisionCmdFlags{app: "my-app"}, "staging")
+	require.ErrorContains(t, err, "error deleting revision 'staging'")
+}
+
+func TestPrintRevisions(t *testing.T) {
+	revisions := []revision{{
+		Name:      "prod",
+		SHA:       "2222222222222222222222222222222222222222",
+		CreatedAt: time.Date(2024, time.May, 2, 10, 0, 0, 0, time.UTC),
+		Size:      4096,
+		Status:    "active",
+		AppUrl:    "https://prod.example.com",
+	}}
+
+	var out bytes.Buffer
+	require.NoError(t, printRevisions(&out, false, 80, revisions))
+	assert.Equal(t, "prod\t2222222222222222222222222222222222222222\t2024-05-02T10:00:00Z\t4096\tactive\thttps://prod.example.com\n", out.String())
+
+	out.Reset()
+	require.NoError(t, printRevisions(&out, true, 200, revisions))
+	assert.Contains(t, out.String(), "NAME")
+	assert.Contains(t, out.String(), "2222222 ")
+	assert.Contains(t, out.String(), "4.0 KiB")
+}
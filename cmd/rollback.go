@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// rollbackPollInterval is how often the revisions are checked while waiting
+// for a rollback to take effect.
+var rollbackPollInterval = 2 * time.Second
+
+type rollbackCmdFlags struct {
+	app     string
+	config  string
+	to      string
+	steps   int
+	yes     bool
+	timeout time.Duration
+}
+
+type rollbackReq struct {
+	RevisionName   string `json:"revision_name"`
+	RolledBackFrom string `json:"rolled_back_from,omitempty"`
+}
+
+// rollbackResult describes a completed rollback.
+type rollbackResult struct {
+	from revision
+	to   revision
+}
+
+// confirmFunc asks the user to confirm an action and reports whether they did.
+type confirmFunc func(prompt string) (bool, error)
+
+func init() {
+	rollbackCmdFlags := rollbackCmdFlags{}
+	rollbackCmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Roll back an app to a previous revision",
+		Long: heredoc.Doc(`
+			Roll back a GitHub Runtime app to a previously deployed revision without re-uploading it.
+			By default the app is rolled back to the revision deployed before the active one.
+			The command waits until the app is serving the target revision.
+			You can specify the app ID using --app flag, --config flag to read from a runtime config file,
+			or it will automatically read from runtime.config.json in the current directory if it exists.
+		`),
+		Example: heredoc.Doc(`
+			$ gh runtime rollback --app my-app
+			# => Rolls back 'my-app' to the revision deployed before the active one
+
+			$ gh runtime rollback --steps 3
+			# => Rolls back to the third revision before the active one
+
+			$ gh runtime rollback --to staging --yes
+			# => Rolls back to the 'staging' revision without asking for confirmation
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newRESTClient()
+			if err != nil {
+				return fmt.Errorf("failed creating REST client: %v", err)
+			}
+
+			result, err := runRollback(client, rollbackCmdFlags, confirmOnTerminal)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Rolled back from '%s' to '%s'\n", result.from.Name, result.to.Name)
+			if result.to.AppUrl != "" {
+				fmt.Printf("%s\n", result.to.AppUrl)
+			}
+			return nil
+		},
+	}
+
+	rollbackCmd.Flags().StringVarP(&rollbackCmdFlags.app, "app", "a", "", "The app ID to roll back")
+	rollbackCmd.Flags().StringVarP(&rollbackCmdFlags.config, "config", "c", "", "Path to runtime config file")
+	rollbackCmd.Flags().StringVar(&rollbackCmdFlags.to, "to", "", "The name of the revision to roll back to")
+	rollbackCmd.Flags().IntVar(&rollbackCmdFlags.steps, "steps", 0, "Roll back this many revisions before the active one (default 1)")
+	rollbackCmd.Flags().BoolVarP(&rollbackCmdFlags.yes, "yes", "y", false, "Do not ask for confirmation")
+	rollbackCmd.Flags().DurationVar(&rollbackCmdFlags.timeout, "timeout", 2*time.Minute, "How long to wait for the rollback to take effect")
+	rollbackCmd.MarkFlagsMutuallyExclusive("to", "steps")
+	rootCmd.AddCommand(rollbackCmd)
+}
+
+func runRollback(client restClient, flags rollbackCmdFlags, confirm confirmFunc) (rollbackResult, error) {
+	if flags.steps < 0 {
+		return rollbackResult{}, fmt.Errorf("--steps must be a positive number")
+	}
+	if flags.to != "" && flags.steps != 0 {
+		return rollbackResult{}, fmt.Errorf("--to and --steps cannot be used together")
+	}
+
+	appName, err := config.ResolveAppName(flags.app, flags.config)
+	if err != nil {
+		return rollbackResult{}, err
+	}
+
+	revisions, err := listRevisions(client, appName)
+	if err != nil {
+		return rollbackResult{}, err
+	}
+
+	result, err := selectRollback(revisions, flags.to, flags.steps)
+	if err != nil {
+		return rollbackResult{}, err
+	}
+
+	if !flags.yes {
+		prompt := fmt.Sprintf("Roll back app '%s' from '%s' (%s) to '%s' (%s)?",
+			appName, result.from.Name, shortSHA(result.from.SHA), result.to.Name, shortSHA(result.to.SHA))
+		ok, err := confirm(prompt)
+		if err != nil {
+			return rollbackResult{}, err
+		}
+		if !ok {
+			return rollbackResult{}, fmt.Errorf("rollback cancelled")
+		}
+	}
+
+	body, err := json.Marshal(rollbackReq{RevisionName: result.to.Name, RolledBackFrom: result.from.Name})
+	if err != nil {
+		return rollbackResult{}, fmt.Errorf("error marshalling request body: %v", err)
+	}
+
+	err = client.Post(fmt.Sprintf("runtime/%s/deployment/rollback", appName), bytes.NewReader(body), nil)
+	if err != nil {
+		return rollbackResult{}, fmt.Errorf("error rolling back app: %w", describeAPIError(err))
+	}
+
+	fmt.Printf("Waiting for '%s' to become active...\n", result.to.Name)
+	active, err := waitForActiveRevision(client, appName, result.to.Name, flags.timeout)
+	if err != nil {
+		return rollbackResult{}, err
+	}
+	result.to = active
+
+	return result, nil
+}
+
+// selectRollback picks the revision to roll back to from revisions, which must
+// be sorted newest first. Without a target name it picks the revision steps
+// places before the active one, skipping failed revisions.
+func selectRollback(revisions []revision, to string, steps int) (rollbackResult, error) {
+	activeIndex := -1
+	for i, rev := range revisions {
+		if rev.Status == revisionStatusActive {
+			activeIndex = i
+			break
+		}
+	}
+	if activeIndex < 0 {
+		return rollbackResult{}, fmt.Errorf("no active revision found to roll back from")
+	}
+	active := revisions[activeIndex]
+
+	if to != "" {
+		for _, rev := range revisions {
+			if rev.Name != to {
+				continue
+			}
+			if rev.Name == active.Name {
+				return rollbackResult{}, fmt.Errorf("revision '%s' is already active", to)
+			}
+			if rev.Status == revisionStatusFailed {
+				return rollbackResult{}, fmt.Errorf("cannot roll back to revision '%s' because its deployment failed", to)
+			}
+			return rollbackResult{from: active, to: rev}, nil
+		}
+		return rollbackResult{}, fmt.Errorf("revision '%s' not found", to)
+	}
+
+	if steps == 0 {
+		steps = 1
+	}
+	var candidates []revision
+	for _, rev := range revisions[activeIndex+1:] {
+		if rev.Status != revisionStatusFailed {
+			candidates = append(candidates, rev)
+		}
+	}
+	if steps > len(candidates) {
+		return rollbackResult{}, fmt.Errorf("cannot roll back %d revisions: only %d previous revisions available", steps, len(candidates))
+	}
+
+	return rollbackResult{from: active, to: candidates[steps-1]}, nil
+}
+
+// waitForActiveRevision polls the revisions of an app until name is active or
+// the timeout expires.
+func waitForActiveRevision(client restClient, appName, name string, timeout time.Duration) (revision, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		revisions, err := listRevisions(client, appName)
+		if err != nil {
+			return revision{}, err
+		}
+		for _, rev := range revisions {
+			if rev.Name == name && rev.Status == revisionStatusActive {
+				return rev, nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return revision{}, fmt.Errorf("timed out waiting for revision '%s' to become active", name)
+		}
+		time.Sleep(rollbackPollInterval)
+	}
+}
+
+// confirmOnTerminal asks for confirmation on the terminal. It fails when the
+// terminal is not interactive, since there is no one to answer.
+func confirmOnTerminal(prompt string) (bool, error) {
+	if !term.IsTerminal(os.Stdin) || !term.IsTerminal(os.Stdout) {
+		return false, fmt.Errorf("--yes is required when not running interactively")
+	}
+	return confirmPrompt(os.Stdin, os.Stdout, prompt)
+}
+
+// confirmPrompt writes prompt to out and reads a yes or no answer from in.
+func confirmPrompt(in io.Reader, out io.Writer, prompt string) (bool, error) {
+	fmt.Fprintf(out, "%s [y/N] ", prompt)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("error reading confirmation: %v", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
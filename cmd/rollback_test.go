@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRevisions() []revision {
+	base := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	return []revision{
+		{Name: "v5", Status: "inactive", CreatedAt: base.Add(5 * time.Hour)},
+		{Name: "v4", Status: revisionStatusActive, CreatedAt: base.Add(4 * time.Hour)},
+		{Name: "v3", Status: revisionStatusFailed, CreatedAt: base.Add(3 * time.Hour)},
+		{Name: "v2", Status: "inactive", CreatedAt: base.Add(2 * time.Hour)},
+		{Name: "v1", Status: "inactive", CreatedAt: base.Add(1 * time.Hour)},
+	}
+}
+
+func TestSelectRollback(t *testing.T) {
+	tests := []struct {
+		name    string
+		to      string
+		steps   int
+		want    string
+		wantErr string
+	}{
+		{name: "default skips failed revisions", want: "v2"},
+		{name: "steps", steps: 2, want: "v1"},
+		{name: "too many steps", steps: 3, wantErr: "only 2 previous revisions available"},
+		{name: "to newer revision", to: "v5", want: "v5"},
+		{name: "to active revision", to: "v4", wantErr: "is already active"},
+		{name: "to failed revision", to: "v3", wantErr: "deployment failed"},
+		{name: "to unknown revision", to: "v9", wantErr: "revision 'v9' not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := selectRollback(testRevisions(), tt.to, tt.steps)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "v4", result.from.Name)
+			assert.Equal(t, tt.want, result.to.Name)
+		})
+	}
+}
+
+func TestSelectRollback_NoActiveRevision(t *testing.T) {
+	_, err := selectRollback([]revision{{Name: "v1", Status: "inactive"}}, "", 0)
+	require.ErrorContains(t, err, "no active revision")
+}
+
+func TestRunRollback_Success(t *testing.T) {
+	rollbackPollInterval = time.Millisecond
+	defer func() { rollbackPollInterval = 2 * time.Second }()
+
+	rolledBack := false
+	var capturedPath string
+	var capturedBody rollbackReq
+	client := &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			revisions := `[{"revision_name":"v2","status":"active","created_at":"2024-05-02T00:00:00Z"},{"revision_name":"v1","status":"inactive","created_at":"2024-05-01T00:00:00Z","app_url":"https://v1.example.com"}]`
+			if rolledBack {
+				revisions = `[{"revision_name":"v2","status":"inactive","created_at":"2024-05-02T00:00:00Z"},{"revision_name":"v1","status":"active","created_at":"2024-05-01T00:00:00Z","app_url":"https://v1.example.com"}]`
+			}
+			return json.Unmarshal([]byte(revisions), resp)
+		},
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			capturedPath = path
+			require.NoError(t, json.NewDecoder(body).Decode(&capturedBody))
+			rolledBack = true
+			return nil
+		},
+	}
+
+	var prompt string
+	confirm := func(p string) (bool, error) {
+		prompt = p
+		return true, nil
+	}
+
+	result, err := runRollback(client, rollbackCmdFlags{app: "my-app", timeout: time.Second}, confirm)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "from 'v2'")
+	assert.Contains(t, prompt, "to 'v1'")
+	assert.Equal(t, "runtime/my-app/deployment/rollback", capturedPath)
+	assert.Equal(t, rollbackReq{RevisionName: "v1", RolledBackFrom: "v2"}, capturedBody)
+	assert.Equal(t, "v2", result.from.Name)
+	assert.Equal(t, "https://v1.example.com", result.to.AppUrl)
+}
+
+func TestRunRollback_Cancelled(t *testing.T) {
+	client := &mockRESTClient{
+		getFunc: mockGetResponse(`[{"revision_name":"v2","status":"active"},{"revision_name":"v1","status":"inactive"}]`),
+	}
+
+	_, err := runRollback(client, rollbackCmdFlags{app: "my-app"}, func(string) (bool, error) { return false, nil })
+	require.ErrorContains(t, err, "rollback cancelled")
+}
+
+func TestRunRollback_Timeout(t *testing.T) {
+	rollbackPollInterval = time.Millisecond
+	defer func() { rollbackPollInterval = 2 * time.Second }()
+
+	client := &mockRESTClient{
+		getFunc:  mockGetResponse(`[{"revision_name":"v2","status":"active"},{"revision_name":"v1","status":"inactive"}]`),
+		postFunc: mockPostSuccess(),
+	}
+
+	_, err := runRollback(client, rollbackCmdFlags{app: "my-app", yes: true, timeout: 10 * time.Millisecond}, nil)
+	require.ErrorContains(t, err, "timed out waiting for revision 'v1'")
+}
+
+func TestConfirmPrompt(t *testing.T) {
+	var out strings.Builder
+	ok, err := confirmPrompt(strings.NewReader("y\n"), &out, "Continue?")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Continue? [y/N] ", out.String())
+
+	ok, err = confirmPrompt(strings.NewReader("\n"), &out, "Continue?")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = confirmPrompt(strings.NewReader(""), &out, "Continue?")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/github/gh-runtime-cli/internal/routes"
+)
+
+// bundleRoutesPath is where the redirects, rewrites and headers of an app are
+// stored inside its bundle.
+const bundleRoutesPath = ".runtime/routes.json"
+
+// maxBundleRoutesSize is the largest routes file read from a bundle, leaving
+// room for routes.MaxRules rules with long paths or header values.
+const maxBundleRoutesSize = routes.MaxRules << 12
+
+// configRoutes returns the routes set in the runtime config file.
+func configRoutes(runtimeConfig config.RuntimeConfig) (routes.Config, error) {
+	routesConfig := routes.Config{Version: routes.Version, SPAFallback: runtimeConfig.SPAFallback}
+	for _, route := range runtimeConfig.Routes {
+		status := route.Status
+		if status == 0 {
+			status = routes.DefaultStatus
+		}
+		routesConfig.Rules = append(routesConfig.Rules, routes.Rule{From: route.From, To: route.To, Status: status, Force: route.Force})
+	}
+	for _, headers := range runtimeConfig.Headers {
+		routesConfig.Headers = append(routesConfig.Headers, routes.HeaderRule{Path: headers.Path, Headers: headers.Headers})
+	}
+
+	err := routesConfig.Validate()
+	if err != nil {
+		return routes.Config{}, fmt.Errorf("invalid routes in runtime config file: %v", err)
+	}
+	return routesConfig, nil
+}
+
+// loadRoutes adds the rules of the _redirects and _headers files in dir to
+// the routes of the runtime config file. The files take precedence, and are
+// read after the build since it usually copies them into dir.
+func loadRoutes(configured routes.Config, dir string) (routes.Config, error) {
+	routesConfig := routes.Config{Version: routes.Version}
+
+	redirectsPath := filepath.Join(dir, routes.RedirectsFile)
+	err := readRoutesFile(redirectsPath, func(r io.Reader) error {
+		rules, err := routes.ParseRedirects(r)
+		routesConfig.Rules = rules
+		return err
+	})
+	if err != nil {
+		return routes.Config{}, err
+	}
+
+	headersPath := filepath.Join(dir, routes.HeadersFile)
+	err = readRoutesFile(headersPath, func(r io.Reader) error {
+		rules, err := routes.ParseHeaders(r)
+		routesConfig.Headers = rules
+		return err
+	})
+	if err != nil {
+		return routes.Config{}, err
+	}
+
+	routesConfig.Merge(configured)
+	err = routesConfig.Validate()
+	if err != nil {
+		return routes.Config{}, fmt.Errorf("invalid routes in '%s': %v", dir, err)
+	}
+	return routesConfig, nil
+}
+
+// readRoutesFile parses the file at path if it exists.
+func readRoutesFile(path string, parse func(io.Reader) error) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading '%s': %v", path, err)
+	}
+	defer f.Close()
+
+	err = parse(f)
+	if err != nil {
+		return fmt.Errorf("error parsing '%s': %v", path, err)
+	}
+	return nil
+}
+
+// writeBundleRoutes adds the routes of the app to a bundle.
+func writeBundleRoutes(zipWriter *zip.Writer, routesConfig routes.Config, opts bundleOptions) (manifestFile, error) {
+	data, err := json.MarshalIndent(routesConfig, "", "  ")
+	if err != nil {
+		return manifestFile{}, fmt.Errorf("error encoding routes: %w", err)
+	}
+	return writeGeneratedFile(zipWriter, bundleRoutesPath, append(data, '\n'), opts)
+}
+
+// readBundleRoutes returns the routes of a bundle, or nil if it does not have
+// any.
+func readBundleRoutes(reader *zip.Reader) (*routes.Config, error) {
+	for _, f := range reader.File {
+		if f.Name != bundleRoutesPath {
+			continue
+		}
+		if f.UncompressedSize64 > maxBundleRoutesSize {
+			return nil, fmt.Errorf("routes are larger than %s", formatByteSize(maxBundleRoutesSize))
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("error reading routes: %w", err)
+		}
+		defer rc.Close()
+
+		data, err := io.ReadAll(io.LimitReader(rc, maxBundleRoutesSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("error reading routes: %w", err)
+		}
+		if len(data) > maxBundleRoutesSize {
+			return nil, fmt.Errorf("routes are larger than %s", formatByteSize(maxBundleRoutesSize))
+		}
+
+		routesConfig := &routes.Config{}
+		err = json.Unmarshal(data, routesConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing routes: %w", err)
+		}
+		if routesConfig.Version != routes.Version {
+			return nil, fmt.Errorf("routes have unsupported version %d", routesConfig.Version)
+		}
+		return routesConfig, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/github/gh-runtime-cli/internal/routes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readZipFile returns the content of the file at name in a zip bundle.
+func readZipFile(t *testing.T, zipPath, name string) []byte {
+	t.Helper()
+	reader, err := zip.OpenReader(zipPath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	for _, f := range reader.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		require.NoError(t, err)
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		return data
+	}
+	t.Fatalf("bundle has no file '%s'", name)
+	return nil
+}
+
+func TestRunPack_Routes(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	writeTestTree(t, tmp, map[string]string{
+		"runtime.config.json": `{
+			"app": "my-app",
+			"spaFallback": true,
+			"routes": [{"from": "/blog/*", "to": "/news/:splat"}],
+			"headers": [{"path": "/*", "headers": {"X-Frame-Options": "DENY"}}]
+		}`,
+		"dist/index.html": "<html></html>",
+		"dist/_redirects": "/old /new 302!\n",
+		"dist/_headers":   "/assets/*\n  Cache-Control: public, max-age=31536000, immutable\n",
+	})
+
+	err = runPack(packCmdFlags{bundle: deployCmdFlags{dir: "dist", reproducible: true}, out: "bundle.zip"})
+	require.NoError(t, err)
+
+	routesConfig := routes.Config{}
+	require.NoError(t, json.Unmarshal(readZipFile(t, "bundle.zip", bundleRoutesPath), &routesConfig))
+	assert.Equal(t, routes.Config{
+		Version: routes.Version,
+		Rules: []routes.Rule{
+			{From: "/old", To: "/new", Status: 302, Force: true},
+			{From: "/blog/*", To: "/news/:splat", Status: 301},
+		},
+		Headers: []routes.HeaderRule{
+			{Path: "/*", Headers: map[string]string{"X-Frame-Options": "DENY"}},
+			{Path: "/assets/*", Headers: map[string]string{"Cache-Control": "public, max-age=31536000, immutable"}},
+		},
+		SPAFallback: true,
+	}, routesConfig)
+
+	result, err := runVerify("bundle.zip", verifyCmdFlags{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Problems)
+	assert.Empty(t, result.Warnings)
+}
+
+func TestLoadRoutes_FilesTakePrecedence(t *testing.T) {
+	tmp := t.TempDir()
+	writeTestTree(t, tmp, map[string]string{
+		"_redirects": "/docs /from-file 302\n",
+		"_headers":   "/*\n  Cache-Control: no-store\n",
+	})
+
+	configured := routes.Config{
+		Version: routes.Version,
+		Rules:   []routes.Rule{{From: "/docs", To: "/from-config", Status: 302}},
+		Headers: []routes.HeaderRule{{Path: "/*", Headers: map[string]string{"Cache-Control": "no-cache", "X-Frame-Options": "DENY"}}},
+	}
+	routesConfig, err := loadRoutes(configured, tmp)
+	require.NoError(t, err)
+
+	_, target, ok := routesConfig.Route("/docs")
+	require.True(t, ok)
+	assert.Equal(t, "/from-file", target)
+	assert.Equal(t, "no-store", routesConfig.HeadersFor("/index.html").Get("Cache-Control"))
+	assert.Equal(t, "DENY", routesConfig.HeadersFor("/index.html").Get("X-Frame-Options"))
+}
+
+func TestRunPack_NoRoutes(t *testing.T) {
+	bundle := packTestBundle(t, map[string]string{"index.html": "<html></html>"})
+
+	reader, err := zip.OpenReader(bundle)
+	require.NoError(t, err)
+	defer reader.Close()
+	routesConfig, err := readBundleRoutes(&reader.Reader)
+	require.NoError(t, err)
+	assert.Nil(t, routesConfig)
+}
+
+func TestReadBundleRoutes_TooLarge(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "bundle.zip")
+	padding := strings.Repeat(" ", maxBundleRoutesSize)
+	writeTestZip(t, bundle, []testArchiveEntry{{name: bundleRoutesPath, content: `{"version": 1}` + padding}})
+
+	reader, err := zip.OpenReader(bundle)
+	require.NoError(t, err)
+	defer reader.Close()
+	_, err = readBundleRoutes(&reader.Reader)
+	require.ErrorContains(t, err, "routes are larger than")
+}
+
+func TestRunDeploy_InvalidConfigRoutes(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	writeTestTree(t, tmp, map[string]string{
+		"runtime.config.json": `{"app": "my-app", "routes": [{"from": "/a/:id", "to": "/b/:slug"}]}`,
+		"dist/index.html":     "<html></html>",
+	})
+
+	err = runDeploy(&mockRESTClient{}, deployCmdFlags{dir: "dist"})
+	require.ErrorContains(t, err, "invalid routes in runtime config file: route '/a/:id': target uses ':slug'")
+}
+
+func TestRunDeploy_InvalidRedirectsFile(t *testing.T) {
+	src := t.TempDir()
+	writeTestTree(t, src, map[string]string{
+		"index.html": "<html></html>",
+		"_redirects": "/a\n",
+	})
+
+	err := runDeploy(&mockRESTClient{}, deployCmdFlags{dir: src, app: "my-app"})
+	require.ErrorContains(t, err, "error parsing '"+filepath.Join(src, "_redirects")+"': line 1: expected a path and a target")
+}
+
+func TestZipDirectory_ReservedRoutesPath(t *testing.T) {
+	src := t.TempDir()
+	writeTestTree(t, src, map[string]string{
+		"index.html":     "<html></html>",
+		bundleRoutesPath: "{}",
+	})
+
+	_, err := zipDirectory(src, filepath.Join(t.TempDir(), "bundle.zip"), bundleOptions{routes: &routes.Config{SPAFallback: true}})
+	require.ErrorContains(t, err, "'.runtime/routes.json' is reserved for the routes of the app")
+}
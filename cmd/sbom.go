@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/github/gh-runtime-cli/internal/provenance"
+	"github.com/github/gh-runtime-cli/internal/sbom"
+)
+
+// sbomExt is appended to the name of a packed bundle to name the file holding
+// its SBOM.
+const sbomExt = ".spdx.json"
+
+// generateSBOM inventories the files of the bundle at bundlePath and the npm
+// packages locked in the project it was built from.
+func (d deployment) generateSBOM(bundlePath, bundleName string) (sbom.Document, error) {
+	digest, err := provenance.FileDigest(bundlePath)
+	if err != nil {
+		return sbom.Document{}, fmt.Errorf("error creating SBOM: %v", err)
+	}
+	files, err := sbom.BundleFiles(bundlePath)
+	if err != nil {
+		return sbom.Document{}, fmt.Errorf("error creating SBOM: %v", err)
+	}
+	dependencies, err := sbom.NPMDependencies(d.root)
+	if err != nil {
+		return sbom.Document{}, fmt.Errorf("error reading npm packages for SBOM: %v", err)
+	}
+
+	// The SBOM only changes with the bundle when SOURCE_DATE_EPOCH is set.
+	created := time.Now()
+	if os.Getenv("SOURCE_DATE_EPOCH") != "" {
+		created, err = sourceDateEpoch()
+		if err != nil {
+			return sbom.Document{}, err
+		}
+	}
+
+	name := d.appName
+	if name == "" {
+		name = strings.TrimSuffix(bundleName, filepath.Ext(bundleName))
+	}
+
+	return sbom.New(sbom.Build{
+		Name:         name,
+		BundleName:   bundleName,
+		BundleDigest: digest,
+		Version:      d.flags.sha,
+		Files:        files,
+		Dependencies: dependencies,
+		Creator:      "gh-runtime-cli-" + Version,
+		Created:      created,
+	}), nil
+}
+
+// uploadSBOM attaches an SBOM to the deployed bundle.
+func (d deployment) uploadSBOM(client restClient, doc sbom.Document) error {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("error encoding SBOM: %v", err)
+	}
+
+	err = client.Post(deploymentURL(d.appName, "sbom", revisionQuery(d.flags.revisionName, d.flags.sha)), bytes.NewReader(body), nil)
+	if err != nil {
+		return fmt.Errorf("error uploading SBOM: %w", describeAPIError(err))
+	}
+	return nil
+}
+
+// writeSBOM writes an SBOM as indented JSON.
+func writeSBOM(path string, doc sbom.Document) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding SBOM: %v", err)
+	}
+	err = os.WriteFile(path, append(data, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing SBOM '%s': %v", path, err)
+	}
+	return nil
+}
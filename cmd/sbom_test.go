@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-runtime-cli/internal/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPackageLock = `{
+	"lockfileVersion": 3,
+	"packages": {
+		"": {"name": "site"},
+		"node_modules/react": {"version": "18.3.1", "license": "MIT"},
+		"node_modules/vite": {"version": "5.4.0", "license": "MIT", "dev": true}
+	}
+}`
+
+func TestRunPack_SBOM(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1714564800")
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	writeTestTree(t, tmp, map[string]string{
+		"package-lock.json": testPackageLock,
+		"dist/index.html":   "<html></html>",
+	})
+
+	err = runPack(packCmdFlags{bundle: deployCmdFlags{dir: "dist", sha: "abc123", reproducible: true, sbom: true}, out: "bundle.zip"})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile("bundle.zip" + sbomExt)
+	require.NoError(t, err)
+	doc := sbom.Document{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, "bundle", doc.Name)
+	assert.Equal(t, "2024-05-01T12:00:00Z", doc.CreationInfo.Created)
+	fileNames := []string{}
+	for _, file := range doc.Files {
+		fileNames = append(fileNames, file.FileName)
+	}
+	assert.Equal(t, []string{"./" + bundleManifestPath, "./index.html"}, fileNames)
+
+	require.Len(t, doc.Packages, 3)
+	assert.Equal(t, "bundle.zip", doc.Packages[0].Name)
+	assert.Equal(t, "abc123", doc.Packages[0].VersionInfo)
+	assert.Equal(t, "react", doc.Packages[1].Name)
+	assert.Equal(t, "vite", doc.Packages[2].Name)
+}
+
+func TestRunDeploy_UploadsSBOM(t *testing.T) {
+	t.Setenv(signingKeyEnv, "")
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	writeTestTree(t, tmp, map[string]string{
+		"package-lock.json": testPackageLock,
+		"dist/index.html":   "<html></html>",
+	})
+
+	posts := map[string][]byte{}
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			data, err := io.ReadAll(body)
+			posts[path] = data
+			return err
+		},
+	}
+
+	err = runDeploy(client, deployCmdFlags{dir: "dist", app: "my-app", revisionName: "v2", sha: "abc123", sbom: true})
+	require.NoError(t, err)
+
+	require.Contains(t, posts, "runtime/my-app/deployment/bundle?revision=abc123&revision_name=v2")
+	data := posts["runtime/my-app/deployment/sbom?revision=abc123&revision_name=v2"]
+	require.NotEmpty(t, data)
+
+	doc := sbom.Document{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "my-app", doc.Name)
+	assert.Len(t, doc.Files, 2)
+	assert.Len(t, doc.Packages, 3)
+}
+
+func TestRunDeploy_InvalidLockfileForSBOM(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	writeTestTree(t, tmp, map[string]string{
+		"package-lock.json": "{",
+		"dist/index.html":   "<html></html>",
+	})
+
+	posted := false
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			posted = true
+			return nil
+		},
+	}
+
+	err = runDeploy(client, deployCmdFlags{dir: filepath.Join(tmp, "dist"), app: "my-app", sha: "abc123", sbom: true})
+	require.ErrorContains(t, err, "error reading npm packages for SBOM")
+	assert.False(t, posted, "nothing should be deployed without its SBOM")
+}
+
+func TestRunGetSBOM(t *testing.T) {
+	var capturedPath string
+	client := &mockRESTClient{
+		getFunc: func(path string, resp interface{}) error {
+			capturedPath = path
+			return json.Unmarshal([]byte(`{"spdxVersion":"SPDX-2.3"}`), resp)
+		},
+	}
+
+	bom, err := runGetSBOM(client, getCmdFlags{app: "my-app", revisionName: "v2"})
+	require.NoError(t, err)
+	assert.Equal(t, "runtime/my-app/deployment/sbom?revision_name=v2", capturedPath)
+	assert.JSONEq(t, `{"spdxVersion":"SPDX-2.3"}`, string(bom))
+}
+
+func TestRunGetSBOM_APIError(t *testing.T) {
+	client := &mockRESTClient{getFunc: mockGetError("not found")}
+
+	_, err := runGetSBOM(client, getCmdFlags{app: "my-app"})
+	require.ErrorContains(t, err, "retrieving SBOM: not found")
+}
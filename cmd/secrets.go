@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+
+	"github.com/cli/go-gh/v2/pkg/text"
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/github/gh-runtime-cli/internal/secrets"
+)
+
+// newSecretScanner returns the scanner for bundles, with the suppressions of
+// the runtime config file.
+func newSecretScanner(scanConfig config.SecretScanConfig) (*secrets.Scanner, error) {
+	suppressions := []secrets.Suppression{}
+	for _, ignore := range scanConfig.Ignore {
+		suppressions = append(suppressions, secrets.Suppression{Path: ignore.Path, Rule: ignore.Rule})
+	}
+
+	scanner, err := secrets.New(suppressions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'secretScan.ignore' in runtime config file: %v", err)
+	}
+	return scanner, nil
+}
+
+// scanBundle scans every file of a zip bundle for secrets.
+func scanBundle(scanner *secrets.Scanner, zipPath string) ([]secrets.Finding, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading zip file '%s': %v", zipPath, err)
+	}
+	defer reader.Close()
+
+	findings := []secrets.Finding{}
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		fileFindings, err := scanZipFile(scanner, f)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning '%s' for secrets: %v", f.Name, err)
+		}
+		findings = append(findings, fileFindings...)
+	}
+	return findings, nil
+}
+
+func scanZipFile(scanner *secrets.Scanner, f *zip.File) ([]secrets.Finding, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return scanner.Scan(f.Name, rc)
+}
+
+// checkSecrets fails the deploy if the bundle holds any secrets, after
+// listing them on w.
+func (d deployment) checkSecrets(zipPath string, w io.Writer) error {
+	findings, err := scanBundle(d.scanner, zipPath)
+	if err != nil {
+		return err
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(w, "Found %s in the bundle:\n", text.Pluralize(len(findings), "possible secret"))
+	for _, finding := range findings {
+		fmt.Fprintf(w, "  %s\n", finding)
+	}
+	fmt.Fprintf(w, "Remove them from the bundle, or suppress false positives with 'secretScan.ignore' in the runtime config file.\n")
+	return fmt.Errorf("deploy blocked: bundle contains %s", text.Pluralize(len(findings), "possible secret"))
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGitHubToken is assembled at runtime so that this file does not trip
+// secret scanners itself.
+var testGitHubToken = "ghp_" + strings.Repeat("a1B2", 9)
+
+func TestRunDeploy_BlocksSecrets(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	writeTestTree(t, tmp, map[string]string{
+		"dist/index.html":    "<html></html>",
+		"dist/.env":          "API_KEY=123\n",
+		"dist/assets/app.js": "const token = '" + testGitHubToken + "'",
+	})
+
+	posted := false
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			posted = true
+			return nil
+		},
+	}
+
+	err = runDeploy(client, deployCmdFlags{dir: "dist", app: "my-app", sha: "abc123"})
+	require.EqualError(t, err, "deploy blocked: bundle contains 2 possible secrets")
+	assert.False(t, posted)
+}
+
+func TestRunDeploy_DryRunBlocksSecrets(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	writeTestTree(t, tmp, map[string]string{
+		"dist/index.html": "<html></html>",
+		"dist/.env":       "API_KEY=123\n",
+	})
+
+	err = runDeploy(&mockRESTClient{}, deployCmdFlags{dir: "dist", app: "my-app", dryRun: true})
+	require.EqualError(t, err, "deploy blocked: bundle contains 1 possible secret")
+}
+
+func TestRunDeploy_SecretScanIgnore(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	writeTestTree(t, tmp, map[string]string{
+		"runtime.config.json":   `{"app": "my-app", "secretScan": {"ignore": [{"path": "docs/**", "rule": "github-token"}]}}`,
+		"dist/index.html":       "<html></html>",
+		"dist/docs/tokens.html": "Tokens look like " + testGitHubToken,
+	})
+
+	posted := false
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			posted = true
+			return nil
+		},
+	}
+
+	err = runDeploy(client, deployCmdFlags{dir: "dist", sha: "abc123"})
+	require.NoError(t, err)
+	assert.True(t, posted)
+}
+
+func TestRunDeploy_InvalidSecretScanIgnore(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	writeTestTree(t, tmp, map[string]string{
+		"runtime.config.json": `{"app": "my-app", "secretScan": {"ignore": [{"path": ".env", "rule": "dotenv"}]}}`,
+		"dist/index.html":     "<html></html>",
+	})
+
+	err = runDeploy(&mockRESTClient{}, deployCmdFlags{dir: "dist"})
+	require.ErrorContains(t, err, "invalid 'secretScan.ignore' in runtime config file")
+}
+
+func TestCheckSecrets_Report(t *testing.T) {
+	src := t.TempDir()
+	writeTestTree(t, src, map[string]string{
+		"index.html":    "<html></html>",
+		"assets/app.js": "x\nconst token = '" + testGitHubToken + "'",
+	})
+	zipPath := t.TempDir() + "/bundle.zip"
+	_, err := zipDirectory(src, zipPath, bundleOptions{})
+	require.NoError(t, err)
+
+	scanner, err := newSecretScanner(config.SecretScanConfig{})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	err = deployment{scanner: scanner}.checkSecrets(zipPath, &out)
+	require.Error(t, err)
+	assert.Equal(t, "Found 1 possible secret in the bundle:\n"+
+		"  assets/app.js:2: GitHub token ghp_******** (github-token)\n"+
+		"Remove them from the bundle, or suppress false positives with 'secretScan.ignore' in the runtime config file.\n", out.String())
+}
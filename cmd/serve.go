@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/github/gh-runtime-cli/internal/routes"
+	"github.com/github/gh-runtime-cli/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+type serveCmdFlags struct {
+	dir    string
+	config string
+	host   string
+	port   int
+	spa    bool
+}
+
+func init() {
+	serveCmdFlags := serveCmdFlags{}
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve an app locally",
+		Long: heredoc.Doc(`
+			Serve a directory locally over HTTP the same way GitHub Runtime serves a deployed app,
+			without creating a revision.
+			If --dir is not given, the directory is resolved the same way as for deploy. The version
+			control, node_modules, .DS_Store and Thumbs.db files that deploy leaves out of an inferred
+			directory are not served, nor are environment files such as .env.
+			Redirects, rewrites, headers and the SPA fallback from the runtime config file and from
+			_redirects and _headers files in the directory are applied as they are once deployed.
+			They are read when the server starts.
+		`),
+		Example: heredoc.Doc(`
+			$ gh runtime serve --dir ./dist
+			# => Serves the 'dist' directory at http://127.0.0.1:3000
+
+			$ gh runtime serve --dir ./dist --port 8080
+			# => Serves the 'dist' directory on port 8080
+
+			$ gh runtime serve --spa
+			# => Serves index.html for routes that do not match a file
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			return runServe(ctx, serveCmdFlags)
+		},
+	}
+
+	serveCmd.Flags().StringVarP(&serveCmdFlags.dir, "dir", "d", "", "The directory to serve")
+	serveCmd.Flags().StringVarP(&serveCmdFlags.config, "config", "c", "", "Path to runtime config file")
+	serveCmd.Flags().StringVar(&serveCmdFlags.host, "host", "127.0.0.1", "The host to listen on")
+	serveCmd.Flags().IntVarP(&serveCmdFlags.port, "port", "p", 3000, "The port to listen on")
+	serveCmd.Flags().BoolVar(&serveCmdFlags.spa, "spa", false, "Serve index.html for routes that do not match a file")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(ctx context.Context, flags serveCmdFlags) error {
+	handler, err := newPreviewHandler(flags)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(flags.host, strconv.Itoa(flags.port)))
+	if err != nil {
+		return fmt.Errorf("error listening on %s:%d: %v", flags.host, flags.port, err)
+	}
+
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("Serving '%s' at http://%s\n", handler.root, listener.Addr())
+	fmt.Printf("Press Ctrl-C to stop.\n")
+
+	err = server.Serve(listener)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving app: %v", err)
+	}
+	return nil
+}
+
+// previewHandler serves a directory the way GitHub Runtime serves a deployed
+// bundle.
+type previewHandler struct {
+	root   string
+	spa    bool
+	routes routes.Config
+	// skipProjectFiles hides bundleIgnoredNames, like deploys of a directory
+	// inferred from the detected framework leave them out.
+	skipProjectFiles bool
+}
+
+// newPreviewHandler resolves the directory to serve and its routes.
+func newPreviewHandler(flags serveCmdFlags) (*previewHandler, error) {
+	runtimeConfig, err := config.LoadRuntimeConfig(flags.config)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, _, inferred, err := resolveDeployDir(".", deployCmdFlags{dir: flags.dir}, runtimeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("directory '%s' does not exist", dir)
+	}
+
+	configured, err := configRoutes(runtimeConfig)
+	if err != nil {
+		return nil, err
+	}
+	routesConfig, err := loadRoutes(configured, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &previewHandler{root: dir, spa: flags.spa || routesConfig.SPAFallback, routes: routesConfig, skipProjectFiles: inferred}, nil
+}
+
+func (h *previewHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	urlPath := path.Clean("/" + r.URL.Path)
+
+	for name, values := range h.routes.HeadersFor(urlPath) {
+		w.Header()[name] = values
+	}
+
+	// Rules only apply to paths that do not match a file, unless forced.
+	rule, target, routed := h.routes.Route(urlPath)
+	if routed && rule.Force {
+		h.serveRoute(w, r, rule, target)
+		return
+	}
+
+	if file, ok := h.resolve(urlPath); ok {
+		http.ServeFile(w, r, file)
+		return
+	}
+
+	if routed {
+		h.serveRoute(w, r, rule, target)
+		return
+	}
+
+	if h.spa && path.Ext(urlPath) == "" {
+		if file, ok := h.resolve("/index.html"); ok {
+			http.ServeFile(w, r, file)
+			return
+		}
+	}
+
+	h.notFound(w, r)
+}
+
+// serveRoute redirects to the target of a rule, or serves the file it names.
+func (h *previewHandler) serveRoute(w http.ResponseWriter, r *http.Request, rule routes.Rule, target string) {
+	if rule.Status != http.StatusOK && rule.Status != http.StatusNotFound {
+		if !strings.Contains(target, "?") && r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, rule.Status)
+		return
+	}
+
+	targetPath, _, _ := strings.Cut(target, "?")
+	file, ok := h.resolve(path.Clean(targetPath))
+	if !ok {
+		h.notFound(w, r)
+		return
+	}
+	if rule.Status == http.StatusNotFound {
+		h.serveNotFoundPage(w, r, file)
+		return
+	}
+	http.ServeFile(w, r, file)
+}
+
+// resolve maps a URL path to a file in the served directory, trying in order
+// the path itself, index.html in the directory it names and the path with an
+// .html extension. Paths that resolve outside of the directory, or that a
+// deploy would leave out, are not served.
+func (h *previewHandler) resolve(urlPath string) (string, bool) {
+	candidates := []string{urlPath, path.Join(urlPath, "index.html")}
+	if path.Ext(urlPath) == "" && urlPath != "/" {
+		candidates = append(candidates, urlPath+".html")
+	}
+
+	for _, candidate := range candidates {
+		if h.skipProjectFiles && isProjectFile(candidate) {
+			continue
+		}
+		file := filepath.Join(h.root, filepath.FromSlash(strings.TrimPrefix(candidate, "/")))
+		info, err := os.Stat(file)
+		if err != nil || !info.Mode().IsRegular() || !h.contains(file) {
+			continue
+		}
+		return file, true
+	}
+	return "", false
+}
+
+// isProjectFile reports whether a URL path goes through one of
+// bundleIgnoredNames, or names an environment file, which the secret scan
+// keeps from being deployed.
+func isProjectFile(urlPath string) bool {
+	for _, element := range strings.Split(urlPath, "/") {
+		if bundleIgnoredNames[element] {
+			return true
+		}
+	}
+	return secrets.IsEnvFile(urlPath)
+}
+
+// contains reports whether file, after resolving symbolic links, is inside the
+// served directory.
+func (h *previewHandler) contains(file string) bool {
+	root, err := filepath.EvalSymlinks(h.root)
+	if err == nil {
+		root, err = filepath.Abs(root)
+	}
+	if err != nil {
+		return false
+	}
+	target, err := filepath.EvalSymlinks(file)
+	if err == nil {
+		target, err = filepath.Abs(target)
+	}
+	if err != nil {
+		return false
+	}
+	return isWithinDir(root, target)
+}
+
+// notFound serves 404.html from the served directory if it exists.
+func (h *previewHandler) notFound(w http.ResponseWriter, r *http.Request) {
+	file, ok := h.resolve("/404.html")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	h.serveNotFoundPage(w, r, file)
+}
+
+// serveNotFoundPage serves an HTML file with a 404 status.
+func (h *previewHandler) serveNotFoundPage(w http.ResponseWriter, r *http.Request, file string) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	w.Write(content)
+}
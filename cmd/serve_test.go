@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-runtime-cli/internal/routes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func servePath(t *testing.T, handler http.Handler, method, urlPath string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, urlPath, nil))
+	body, err := io.ReadAll(rec.Result().Body)
+	require.NoError(t, err)
+	return rec.Code, string(body)
+}
+
+func TestPreviewHandler_ServesFiles(t *testing.T) {
+	tmp := t.TempDir()
+	writeTestTree(t, tmp, map[string]string{
+		"index.html":      "home",
+		"about.html":      "about",
+		"docs/index.html": "docs",
+		"assets/app.js":   "console.log('hi')",
+	})
+	handler := &previewHandler{root: tmp}
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/", http.StatusOK, "home"},
+		{"/about", http.StatusOK, "about"},
+		{"/docs/", http.StatusOK, "docs"},
+		{"/assets/app.js", http.StatusOK, "console.log('hi')"},
+		{"/missing", http.StatusNotFound, "404 page not found\n"},
+		{"/../../etc/passwd", http.StatusNotFound, "404 page not found\n"},
+	}
+	for _, tt := range tests {
+		code, body := servePath(t, handler, http.MethodGet, tt.path)
+		assert.Equal(t, tt.code, code, tt.path)
+		assert.Equal(t, tt.body, body, tt.path)
+	}
+
+	code, _ := servePath(t, handler, http.MethodPost, "/")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
+
+func TestPreviewHandler_SPAFallback(t *testing.T) {
+	tmp := t.TempDir()
+	writeTestTree(t, tmp, map[string]string{"index.html": "app"})
+	handler := &previewHandler{root: tmp, spa: true}
+
+	code, body := servePath(t, handler, http.MethodGet, "/users/42")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "app", body)
+
+	// Missing assets are not rewritten to index.html.
+	code, _ = servePath(t, handler, http.MethodGet, "/assets/missing.js")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestPreviewHandler_Custom404(t *testing.T) {
+	tmp := t.TempDir()
+	writeTestTree(t, tmp, map[string]string{"index.html": "home", "404.html": "not here"})
+	handler := &previewHandler{root: tmp}
+
+	code, body := servePath(t, handler, http.MethodGet, "/missing")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, "not here", body)
+}
+
+func TestPreviewHandler_SymlinkOutsideRoot(t *testing.T) {
+	skipWithoutSymlinks(t)
+	tmp := t.TempDir()
+	site := filepath.Join(tmp, "site")
+	writeTestTree(t, tmp, map[string]string{"site/index.html": "home", "secret.txt": "secret"})
+	require.NoError(t, os.Symlink(filepath.Join(tmp, "secret.txt"), filepath.Join(site, "secret.txt")))
+	handler := &previewHandler{root: site}
+
+	code, _ := servePath(t, handler, http.MethodGet, "/secret.txt")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestNewPreviewHandler_InferredDir(t *testing.T) {
+	chdirTestProject(t, map[string]string{
+		"index.html":                "home",
+		".git/config":               "[core]",
+		".env":                      "TOKEN=abc",
+		"node_modules/pkg/index.js": "module.exports = {}",
+	})
+
+	handler, err := newPreviewHandler(serveCmdFlags{})
+	require.NoError(t, err)
+	assert.Equal(t, ".", handler.root)
+
+	code, body := servePath(t, handler, http.MethodGet, "/")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "home", body)
+	code, _ = servePath(t, handler, http.MethodGet, "/.git/config")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = servePath(t, handler, http.MethodGet, "/node_modules/pkg/index.js")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = servePath(t, handler, http.MethodGet, "/.env")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// A directory given explicitly is served as it is, like it is deployed.
+	handler, err = newPreviewHandler(serveCmdFlags{dir: "."})
+	require.NoError(t, err)
+	code, _ = servePath(t, handler, http.MethodGet, "/node_modules/pkg/index.js")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestNewPreviewHandler_DirNotExist(t *testing.T) {
+	_, err := newPreviewHandler(serveCmdFlags{dir: "/nonexistent/path"})
+	require.ErrorContains(t, err, "does not exist")
+}
+
+func TestPreviewHandler_Routes(t *testing.T) {
+	tmp := t.TempDir()
+	writeTestTree(t, tmp, map[string]string{
+		"index.html":    "home",
+		"app.html":      "app",
+		"old.html":      "old",
+		"missing.html":  "custom missing",
+		"promo.html":    "promo",
+		"assets/app.js": "js",
+	})
+	handler := &previewHandler{root: tmp, routes: routes.Config{
+		Rules: []routes.Rule{
+			{From: "/blog/:slug", To: "/news/:slug", Status: 301},
+			{From: "/app/*", To: "/app.html", Status: 200},
+			{From: "/old", To: "/new", Status: 302},
+			{From: "/assets/*", To: "/index.html", Status: 200},
+			{From: "/gone/*", To: "/missing.html", Status: 404},
+			{From: "/promo.html", To: "/app.html", Status: 200, Force: true},
+		},
+		Headers: []routes.HeaderRule{
+			{Path: "/*", Headers: map[string]string{"X-Frame-Options": "DENY"}},
+			{Path: "/assets/*", Headers: map[string]string{"Cache-Control": "public, max-age=31536000, immutable"}},
+		},
+	}}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/blog/hello?ref=feed", nil))
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/news/hello?ref=feed", rec.Header().Get("Location"))
+	assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/app/settings/profile", http.StatusOK, "app"},
+		{"/assets/app.js", http.StatusOK, "js"},
+		{"/assets/other.js", http.StatusOK, "home"},
+		{"/gone/page", http.StatusNotFound, "custom missing"},
+		{"/promo.html", http.StatusOK, "app"},
+	}
+	for _, tt := range tests {
+		code, body := servePath(t, handler, http.MethodGet, tt.path)
+		assert.Equal(t, tt.code, code, tt.path)
+		assert.Equal(t, tt.body, body, tt.path)
+	}
+
+	// Files shadow rules that are not forced.
+	code, body := servePath(t, handler, http.MethodGet, "/old")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "old", body)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/app.js", nil))
+	assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+}
+
+func TestNewPreviewHandler_Routes(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	writeTestTree(t, tmp, map[string]string{
+		"dist/index.html":     "home",
+		"dist/_redirects":     "/old /new 302\n",
+		"runtime.config.json": `{"app":"my-app","dir":"dist","spaFallback":true,"routes":[{"from":"/old","to":"/ignored"},{"from":"/docs/*","to":"https://docs.example.com/:splat"}]}`,
+	})
+
+	handler, err := newPreviewHandler(serveCmdFlags{})
+	require.NoError(t, err)
+	assert.True(t, handler.spa)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/old", nil))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/new", rec.Header().Get("Location"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/start", nil))
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "https://docs.example.com/start", rec.Header().Get("Location"))
+
+	code, body := servePath(t, handler, http.MethodGet, "/users/42")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "home", body)
+}
+
+func TestNewPreviewHandler_InvalidRoutes(t *testing.T) {
+	tmp := t.TempDir()
+	writeTestTree(t, tmp, map[string]string{
+		"index.html": "home",
+		"_redirects": "/a /b 418\n",
+	})
+
+	_, err := newPreviewHandler(serveCmdFlags{dir: tmp})
+	require.ErrorContains(t, err, "unsupported status 418")
+}
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/text"
+	"github.com/github/gh-runtime-cli/internal/config"
+	"github.com/github/gh-runtime-cli/internal/provenance"
+	"github.com/spf13/cobra"
+)
+
+type verifyCmdFlags struct {
+	config      string
+	maxSize     string
+	maxFileSize string
+	json        bool
+	provenance  string
+	publicKey   string
+}
+
+// verifyResult is the outcome of verifying a bundle.
+type verifyResult struct {
+	Bundle   string   `json:"bundle"`
+	Files    int      `json:"files"`
+	Problems []string `json:"problems"`
+	Warnings []string `json:"warnings"`
+	// Provenance is set when the bundle matches signed provenance.
+	Provenance *verifiedProvenance `json:"provenance,omitempty"`
+}
+
+// verifiedProvenance summarizes provenance whose signature and digest were
+// verified.
+type verifiedProvenance struct {
+	KeyID   string `json:"key_id"`
+	Builder string `json:"builder"`
+	Commit  string `json:"commit,omitempty"`
+}
+
+func init() {
+	verifyCmdFlags := verifyCmdFlags{}
+	verifyCmd := &cobra.Command{
+		Use:   "verify BUNDLE",
+		Short: "Check a bundle before deploying it",
+		Long: heredoc.Docf(`
+			Checks a zip bundle, such as one written by 'gh runtime pack', without contacting GitHub.
+			The bundle fails verification if it has entries that escape the bundle, duplicate entries,
+			entries larger than the size limits, signs of a zip bomb, no index.html at its root, files
+			that do not match its manifest at %[1]s%[2]s%[1]s, or invalid routes at %[1]s%[3]s%[1]s.
+			Size limits are read from --max-size and --max-file-size, or from the runtime config file.
+			With --provenance, the signed provenance written by 'gh runtime pack' is also checked against
+			the public key given with --public-key, and the bundle must match the digest it records.
+		`, "`", bundleManifestPath, bundleRoutesPath),
+		Example: heredoc.Doc(`
+			$ gh runtime verify bundle.zip
+			# => Checks bundle.zip and lists any problems
+
+			$ gh runtime verify bundle.zip --max-file-size 5MB --json
+			# => Checks bundle.zip with a 5MB limit per file and prints the result as JSON
+
+			$ gh runtime verify bundle.zip --provenance bundle.zip.intoto.jsonl --public-key signing.pub
+			# => Also checks that bundle.zip is the bundle described by the signed provenance
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := runVerify(args[0], verifyCmdFlags)
+			if err != nil {
+				return err
+			}
+
+			if verifyCmdFlags.json {
+				err = printJSON(os.Stdout, result)
+				if err != nil {
+					return err
+				}
+			} else {
+				printVerifyResult(os.Stdout, os.Stderr, result)
+			}
+
+			if len(result.Problems) > 0 {
+				return fmt.Errorf("bundle '%s' failed verification", args[0])
+			}
+			return nil
+		},
+	}
+	verifyCmd.Flags().StringVarP(&verifyCmdFlags.config, "config", "c", "", "Path to runtime config file")
+	verifyCmd.Flags().StringVar(&verifyCmdFlags.maxSize, "max-size", "", "Fail if the bundle is larger than this size (e.g. '25MB')")
+	verifyCmd.Flags().StringVar(&verifyCmdFlags.maxFileSize, "max-file-size", "", "Fail if any file is larger than this size (e.g. '5MB')")
+	verifyCmd.Flags().BoolVar(&verifyCmdFlags.json, "json", false, "Output JSON")
+	verifyCmd.Flags().StringVar(&verifyCmdFlags.provenance, "provenance", "", "Path to the signed provenance of the bundle")
+	verifyCmd.Flags().StringVar(&verifyCmdFlags.publicKey, "public-key", "", "Path to the PEM encoded ed25519 public key the provenance is signed with")
+	rootCmd.AddCommand(verifyCmd)
+}
+
+// runVerify checks a bundle and returns what was found. An error is only
+// returned if the bundle could not be checked at all.
+func runVerify(bundlePath string, flags verifyCmdFlags) (verifyResult, error) {
+	if (flags.provenance == "") != (flags.publicKey == "") {
+		return verifyResult{}, fmt.Errorf("--provenance and --public-key must be used together")
+	}
+
+	runtimeConfig, err := config.LoadRuntimeConfig(flags.config)
+	if err != nil {
+		return verifyResult{}, err
+	}
+	budget, err := resolveBundleBudget(flags.maxSize, flags.maxFileSize, runtimeConfig.MaxSize, runtimeConfig.MaxFileSize)
+	if err != nil {
+		return verifyResult{}, err
+	}
+
+	result, err := verifyBundle(bundlePath, budget)
+	if err != nil || flags.provenance == "" {
+		return result, err
+	}
+
+	publicKey, err := loadPublicKey(flags.publicKey)
+	if err != nil {
+		return result, err
+	}
+	envelope, err := readProvenance(flags.provenance)
+	if err != nil {
+		return result, err
+	}
+
+	verified, err := verifyBundleProvenance(bundlePath, envelope, publicKey)
+	if err != nil {
+		result.Problems = append(result.Problems, err.Error())
+	}
+	result.Provenance = verified
+	return result, nil
+}
+
+// verifyBundleProvenance checks that provenance was signed with publicKey and
+// describes the bundle at bundlePath.
+func verifyBundleProvenance(bundlePath string, envelope provenance.Envelope, publicKey ed25519.PublicKey) (*verifiedProvenance, error) {
+	statement, err := provenance.Verify(envelope, publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("provenance signature is invalid: %v", err)
+	}
+
+	digest, err := provenance.FileDigest(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading bundle '%s': %v", bundlePath, err)
+	}
+	if !statement.CoversDigest(digest) {
+		return nil, fmt.Errorf("bundle digest sha256:%s does not match the provenance", digest)
+	}
+
+	keyID, err := provenance.KeyID(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &verifiedProvenance{KeyID: keyID, Builder: statement.Predicate.RunDetails.Builder.ID, Commit: statement.Commit()}, nil
+}
+
+// verifyBundle checks that a bundle is safe to deploy, fits in the budget,
+// has an index.html and matches its manifest.
+func verifyBundle(bundlePath string, budget bundleBudget) (verifyResult, error) {
+	result := verifyResult{Bundle: bundlePath, Problems: []string{}, Warnings: []string{}}
+
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return result, fmt.Errorf("error reading bundle '%s': %v", bundlePath, err)
+	}
+
+	inspection, err := inspectZipBundle(bundlePath)
+	if err != nil {
+		return result, fmt.Errorf("error reading bundle '%s': %v", bundlePath, err)
+	}
+	result.Problems = append(result.Problems, inspection.problems...)
+
+	for name := range inspection.files {
+		if name != bundleManifestPath {
+			result.Files++
+		}
+	}
+
+	if _, ok := inspection.files["index.html"]; !ok {
+		result.Problems = append(result.Problems, "bundle has no index.html at its root")
+	}
+
+	entries := []bundleEntry{}
+	for _, entry := range inspection.entries {
+		if entry.name != bundleManifestPath {
+			entries = append(entries, entry)
+		}
+	}
+	err = budget.check(newSizeReport(entries, info.Size()))
+	if err != nil {
+		result.Problems = append(result.Problems, err.Error())
+	}
+
+	manifestProblems, err := checkBundleManifest(bundlePath, inspection.files)
+	if err != nil {
+		result.Problems = append(result.Problems, err.Error())
+	} else if manifestProblems == nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("bundle has no manifest at %s, its files could not be checked against it", bundleManifestPath))
+	} else {
+		result.Problems = append(result.Problems, manifestProblems...)
+	}
+
+	err = checkBundleRoutes(bundlePath)
+	if err != nil {
+		result.Problems = append(result.Problems, err.Error())
+	}
+
+	return result, nil
+}
+
+// checkBundleManifest compares the files of a bundle with its manifest. It
+// returns nil if the bundle has no manifest, and an empty list if every file
+// matches.
+func checkBundleManifest(bundlePath string, files map[string]manifestFile) ([]string, error) {
+	reader, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	manifest, err := readBundleManifest(&reader.Reader)
+	if err != nil || manifest == nil {
+		return nil, err
+	}
+	if manifest.Version != bundleManifestVersion {
+		return nil, fmt.Errorf("bundle manifest has unsupported version %d", manifest.Version)
+	}
+
+	problems := []string{}
+	listed := map[string]bool{}
+	for _, expected := range manifest.Files {
+		listed[expected.Path] = true
+		actual, ok := files[expected.Path]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("file '%s' is listed in the manifest but missing from the bundle", expected.Path))
+		case actual.Size != expected.Size || actual.SHA256 != expected.SHA256:
+			problems = append(problems, fmt.Sprintf("file '%s' does not match the manifest", expected.Path))
+		}
+	}
+
+	unlisted := []string{}
+	for name := range files {
+		if name != bundleManifestPath && !listed[name] {
+			unlisted = append(unlisted, name)
+		}
+	}
+	sort.Strings(unlisted)
+	for _, name := range unlisted {
+		problems = append(problems, fmt.Sprintf("file '%s' is not listed in the manifest", name))
+	}
+
+	return problems, nil
+}
+
+// checkBundleRoutes validates the routes of a bundle, if it has any.
+func checkBundleRoutes(bundlePath string) error {
+	reader, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	routesConfig, err := readBundleRoutes(&reader.Reader)
+	if err != nil || routesConfig == nil {
+		return err
+	}
+	err = routesConfig.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid routes at %s: %v", bundleRoutesPath, err)
+	}
+	return nil
+}
+
+// printVerifyResult prints the problems found in a bundle to w and its
+// warnings to warnings.
+func printVerifyResult(w, warnings io.Writer, result verifyResult) {
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(warnings, "warning: %s\n", warning)
+	}
+
+	if result.Provenance != nil {
+		fmt.Fprintf(w, "Provenance signed by key %s: built by %s", shortSHA(result.Provenance.KeyID), result.Provenance.Builder)
+		if result.Provenance.Commit != "" {
+			fmt.Fprintf(w, " from commit %s", shortSHA(result.Provenance.Commit))
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(result.Problems) == 0 {
+		fmt.Fprintf(w, "Bundle '%s' is valid (%s)\n", result.Bundle, text.Pluralize(result.Files, "file"))
+		return
+	}
+
+	fmt.Fprintf(w, "Bundle '%s' has %s:\n", result.Bundle, text.Pluralize(len(result.Problems), "problem"))
+	for _, problem := range result.Problems {
+		fmt.Fprintf(w, "  %s\n", problem)
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// packTestBundle packs files into a bundle and returns its path.
+func packTestBundle(t *testing.T, files map[string]string) string {
+	t.Helper()
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "dist")
+	writeTestTree(t, src, files)
+	out := filepath.Join(tmp, "bundle.zip")
+	require.NoError(t, runPack(packCmdFlags{bundle: deployCmdFlags{dir: src, reproducible: true}, out: out}))
+	return out
+}
+
+func TestVerifyBundle_Problems(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []testArchiveEntry
+		budget  bundleBudget
+		want    string
+	}{
+		{"missing index.html", []testArchiveEntry{{name: "about.html", content: "x"}}, bundleBudget{}, "no index.html"},
+		{"path traversal", []testArchiveEntry{{name: "index.html", content: "x"}, {name: "../evil.html", content: "x"}}, bundleBudget{}, "refers to a parent directory"},
+		{"duplicate", []testArchiveEntry{{name: "index.html", content: "a"}, {name: "index.html", content: "b"}}, bundleBudget{}, "appears more than once"},
+		{"invalid routes", []testArchiveEntry{{name: "index.html", content: "x"}, {name: bundleRoutesPath, content: `{"version":1,"rules":[{"from":"old","to":"/new","status":301}]}`}}, bundleBudget{}, "invalid routes at .runtime/routes.json: route 'old': path must start with '/'"},
+		{"oversized entry", []testArchiveEntry{{name: "index.html", content: "0123456789"}}, bundleBudget{maxFileSize: 5}, "exceeds the maximum file size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle := filepath.Join(t.TempDir(), "bundle.zip")
+			writeTestZip(t, bundle, tt.entries)
+
+			result, err := verifyBundle(bundle, tt.budget)
+			require.NoError(t, err)
+			require.NotEmpty(t, result.Problems)
+			assert.Contains(t, result.Problems[0], tt.want)
+		})
+	}
+}
+
+func TestVerifyBundle_NoManifest(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "bundle.zip")
+	writeTestZip(t, bundle, []testArchiveEntry{{name: "index.html", content: "x"}})
+
+	result, err := verifyBundle(bundle, bundleBudget{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Problems)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "no manifest")
+}
+
+func TestVerifyBundle_ManifestMismatch(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "bundle.zip")
+	writeTestZip(t, bundle, []testArchiveEntry{
+		{name: "index.html", content: "tampered"},
+		{name: "extra.js", content: "alert(1)"},
+		{name: bundleManifestPath, content: `{"version":1,"files":[
+			{"path":"index.html","size":5,"sha256":"586a866f990ab55e36decfffc2011f172e4452d5141c939a5436baffba11111d"},
+			{"path":"gone.css","size":1,"sha256":"00"}
+		]}`},
+	})
+
+	result, err := verifyBundle(bundle, bundleBudget{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"file 'index.html' does not match the manifest",
+		"file 'gone.css' is listed in the manifest but missing from the bundle",
+		"file 'extra.js' is not listed in the manifest",
+	}, result.Problems)
+}
+
+func TestVerifyBundle_PackedBundleIsValid(t *testing.T) {
+	bundle := packTestBundle(t, map[string]string{"index.html": "<html></html>", "docs/index.html": "docs"})
+
+	result, err := verifyBundle(bundle, bundleBudget{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Problems)
+	assert.Equal(t, 2, result.Files)
+}
+
+func TestVerifyBundle_NotAZip(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "bundle.zip")
+	writeTestTree(t, filepath.Dir(bundle), map[string]string{"bundle.zip": "not a zip"})
+
+	_, err := verifyBundle(bundle, bundleBudget{})
+	require.ErrorContains(t, err, "error reading bundle")
+}
+
+func TestPrintVerifyResult(t *testing.T) {
+	var out, warnings bytes.Buffer
+	printVerifyResult(&out, &warnings, verifyResult{Bundle: "bundle.zip", Problems: []string{"bundle has no index.html at its root"}, Warnings: []string{"careful"}})
+	assert.Equal(t, "Bundle 'bundle.zip' has 1 problem:\n  bundle has no index.html at its root\n", out.String())
+	assert.Equal(t, "warning: careful\n", warnings.String())
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// watchPollInterval is how often watched files are checked for changes.
+	watchPollInterval = 500 * time.Millisecond
+	// watchDebounce is how long files must stay unchanged before a redeploy starts.
+	watchDebounce = 300 * time.Millisecond
+)
+
+// fileState is what a fileWatcher compares to detect that a file changed.
+type fileState struct {
+	size    int64
+	modTime time.Time
+	mode    fs.FileMode
+}
+
+// fileWatcher detects changes to files below a set of directories by
+// periodically comparing snapshots of them.
+type fileWatcher struct {
+	roots []string
+	// excluded holds absolute paths whose contents are not watched.
+	excluded []string
+	interval time.Duration
+	debounce time.Duration
+}
+
+// newDeployWatcher returns a watcher for the inputs of a deployment. If the
+// deployment has a build command, the whole project is watched except for the
+// build output, which the build itself rewrites. The build output is watched
+// too when it is the project itself, since excluding it would leave nothing
+// to watch. Otherwise only the deploy directory is watched.
+func newDeployWatcher(d deployment) (*fileWatcher, error) {
+	w := &fileWatcher{interval: watchPollInterval, debounce: watchDebounce}
+	if d.flags.buildCmd == "" || d.flags.skipBuild {
+		w.roots = []string{d.flags.dir}
+		return w, nil
+	}
+
+	outputDir, err := filepath.Abs(d.flags.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving directory '%s': %v", d.flags.dir, err)
+	}
+	projectDir, err := filepath.Abs(".")
+	if err != nil {
+		return nil, fmt.Errorf("error resolving the current directory: %v", err)
+	}
+	w.roots = []string{"."}
+	if !isWithinDir(outputDir, projectDir) {
+		w.excluded = []string{outputDir}
+	}
+	return w, nil
+}
+
+// snapshot returns the state of every watched file, skipping version control
+// and dependency directories, see bundleIgnoredNames.
+func (w *fileWatcher) snapshot() (map[string]fileState, error) {
+	files := map[string]fileState{}
+	for _, root := range w.roots {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				// Files can disappear while a build is running; pick them up on the next poll.
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+
+			if (path != root && bundleIgnoredNames[entry.Name()]) || w.isExcluded(path) {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			files[path] = fileState{size: info.Size(), modTime: info.ModTime(), mode: info.Mode()}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error watching '%s': %v", root, err)
+		}
+	}
+	return files, nil
+}
+
+func (w *fileWatcher) isExcluded(path string) bool {
+	if len(w.excluded) == 0 {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, excluded := range w.excluded {
+		if isWithinDir(excluded, abs) {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForChange blocks until the watched files differ from previous and have
+// then stayed unchanged for the debounce period. It returns the new snapshot,
+// or the context's error if ctx is done first.
+func (w *fileWatcher) waitForChange(ctx context.Context, previous map[string]fileState) (map[string]fileState, error) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	current := previous
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case now := <-ticker.C:
+			next, err := w.snapshot()
+			if err != nil {
+				return nil, err
+			}
+
+			if !snapshotsEqual(current, next) {
+				current = next
+				lastChange = now
+				continue
+			}
+			if !lastChange.IsZero() && now.Sub(lastChange) >= w.debounce {
+				return current, nil
+			}
+		}
+	}
+}
+
+func snapshotsEqual(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		other, ok := b[path]
+		if !ok || other.size != state.size || !other.modTime.Equal(state.modTime) || other.mode != state.mode {
+			return false
+		}
+	}
+	return true
+}
+
+// runWatch deploys once and then redeploys every time the deployment's inputs
+// change, until ctx is cancelled. Failed deploys are reported and the watch
+// continues.
+func runWatch(ctx context.Context, client restClient, flags deployCmdFlags) error {
+	if flags.revisionName == "" {
+		return fmt.Errorf("--watch requires --revision-name so that changes are not deployed to the default revision")
+	}
+	if flags.dryRun {
+		return fmt.Errorf("--watch cannot be used with --dry-run")
+	}
+	if flags.ref != "" {
+		return fmt.Errorf("--watch cannot be used with --ref")
+	}
+	if flags.archive != "" {
+		return fmt.Errorf("--watch cannot be used with --archive")
+	}
+
+	d, err := resolveDeployment(flags)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := newDeployWatcher(d)
+	if err != nil {
+		return err
+	}
+
+	return watchDeployment(ctx, client, flags, d, watcher)
+}
+
+// watchDeployment deploys d, then redeploys whenever the watched files change.
+// The deployment is resolved from flags again before every redeploy, since
+// the commit and the runtime config file may have changed along with the
+// files.
+func watchDeployment(ctx context.Context, client restClient, flags deployCmdFlags, d deployment, watcher *fileWatcher) error {
+	var resolveErr error
+	for {
+		// Snapshot before deploying so that changes made during the deploy
+		// trigger another one.
+		snapshot, err := watcher.snapshot()
+		if err != nil {
+			return err
+		}
+
+		if resolveErr != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", resolveErr)
+		} else {
+			deployAndReport(client, d)
+		}
+
+		fmt.Printf("Watching for changes. Press Ctrl-C to stop.\n")
+		_, err = watcher.waitForChange(ctx, snapshot)
+		if ctx.Err() != nil {
+			fmt.Printf("Stopped watching\n")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Change detected, redeploying\n")
+		d, resolveErr = resolveDeployment(flags)
+	}
+}
+
+// deployAndReport runs a deploy and prints the URL of the revision if it
+// succeeded, or the error if it did not.
+func deployAndReport(client restClient, d deployment) {
+	err := d.run(client)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return
+	}
+
+	appUrl, err := runGet(client, getCmdFlags{app: d.appName, revisionName: d.flags.revisionName})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return
+	}
+	fmt.Printf("Revision '%s' is live at %s\n", d.flags.revisionName, appUrl)
+}
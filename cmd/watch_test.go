@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/github/gh-runtime-cli/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWatcher_Snapshot(t *testing.T) {
+	tmp := t.TempDir()
+	writeTestTree(t, tmp, map[string]string{
+		"src/main.js":               "console.log('hi')",
+		"dist/index.html":           "<html></html>",
+		"node_modules/pkg/index.js": "module.exports = {}",
+	})
+
+	w := &fileWatcher{roots: []string{tmp}, excluded: []string{filepath.Join(tmp, "dist")}}
+	snapshot, err := w.snapshot()
+	require.NoError(t, err)
+
+	assert.Contains(t, snapshot, filepath.Join(tmp, "src", "main.js"))
+	assert.NotContains(t, snapshot, filepath.Join(tmp, "dist", "index.html"))
+	assert.NotContains(t, snapshot, filepath.Join(tmp, "node_modules", "pkg", "index.js"))
+}
+
+func TestNewDeployWatcher(t *testing.T) {
+	chdirTestProject(t, map[string]string{
+		"src/main.js":     "console.log('hi')",
+		"dist/index.html": "<html></html>",
+	})
+
+	tests := []struct {
+		name       string
+		flags      deployCmdFlags
+		watched    []string
+		notWatched []string
+	}{
+		{"without build", deployCmdFlags{dir: "dist"}, []string{"dist/index.html"}, []string{"src/main.js"}},
+		{"build output excluded", deployCmdFlags{dir: "dist", buildCmd: "npm run build"}, []string{"src/main.js"}, []string{"dist/index.html"}},
+		{"build output is the project", deployCmdFlags{dir: ".", buildCmd: "npm run build"}, []string{"src/main.js", "dist/index.html"}, nil},
+		{"build output contains the project", deployCmdFlags{dir: "..", buildCmd: "npm run build"}, []string{"src/main.js", "dist/index.html"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := newDeployWatcher(deployment{flags: tt.flags})
+			require.NoError(t, err)
+			snapshot, err := w.snapshot()
+			require.NoError(t, err)
+
+			for _, name := range tt.watched {
+				assert.Contains(t, snapshot, filepath.FromSlash(name))
+			}
+			for _, name := range tt.notWatched {
+				assert.NotContains(t, snapshot, filepath.FromSlash(name))
+			}
+		})
+	}
+}
+
+func TestFileWatcher_WaitForChange(t *testing.T) {
+	tmp := t.TempDir()
+	writeTestTree(t, tmp, map[string]string{"index.html": "<html></html>"})
+
+	w := &fileWatcher{roots: []string{tmp}, interval: 10 * time.Millisecond, debounce: 30 * time.Millisecond}
+	before, err := w.snapshot()
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		writeTestTree(t, tmp, map[string]string{"app.js": "console.log('hi')"})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	after, err := w.waitForChange(ctx, before)
+	require.NoError(t, err)
+	assert.Contains(t, after, filepath.Join(tmp, "app.js"))
+}
+
+func TestFileWatcher_WaitForChangeCancelled(t *testing.T) {
+	tmp := t.TempDir()
+	w := &fileWatcher{roots: []string{tmp}, interval: 10 * time.Millisecond, debounce: 10 * time.Millisecond}
+	before, err := w.snapshot()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = w.waitForChange(ctx, before)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRunWatch_RequiresRevisionName(t *testing.T) {
+	err := runWatch(context.Background(), &mockRESTClient{}, deployCmdFlags{dir: "dist", app: "my-app"})
+	require.ErrorContains(t, err, "--watch requires --revision-name")
+}
+
+func TestWatchDeployment_RedeploysOnChange(t *testing.T) {
+	tmp := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmp))
+	defer os.Chdir(origDir)
+
+	writeTestTree(t, tmp, map[string]string{"dist/index.html": "<html></html>"})
+
+	var mu sync.Mutex
+	var postedPaths []string
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			mu.Lock()
+			defer mu.Unlock()
+			postedPaths = append(postedPaths, path)
+			return nil
+		},
+		getFunc: func(path string, resp interface{}) error {
+			return json.Unmarshal([]byte(`{"app_url":"https://preview.example.com"}`), resp)
+		},
+	}
+	deploys := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(postedPaths)
+	}
+
+	flags := deployCmdFlags{dir: "dist", app: "my-app", revisionName: "preview"}
+	d, err := resolveDeployment(flags)
+	require.NoError(t, err)
+	watcher := &fileWatcher{roots: []string{"dist"}, interval: 10 * time.Millisecond, debounce: 20 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- watchDeployment(ctx, client, flags, d, watcher)
+	}()
+
+	require.Eventually(t, func() bool { return deploys() == 1 }, 5*time.Second, 10*time.Millisecond)
+	writeTestTree(t, tmp, map[string]string{"dist/app.js": "console.log('hi')"})
+	require.Eventually(t, func() bool { return deploys() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Contains(t, postedPaths[1], "revision_name=preview")
+}
+
+func TestWatchDeployment_ResolvesCommitBeforeRedeploy(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
+	t.Setenv("GITHUB_HEAD_REF", "")
+	t.Setenv("GITHUB_REF_TYPE", "")
+	repo := initTestRepo(t, map[string]string{"dist/index.html": "<html></html>"})
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(repo))
+	defer os.Chdir(origDir)
+
+	// The first deploy is held until the change is committed, so the watcher
+	// only sees the change once the commit exists.
+	committed := make(chan struct{})
+	var mu sync.Mutex
+	var postedPaths []string
+	client := &mockRESTClient{
+		postFunc: func(path string, body io.Reader, resp interface{}) error {
+			mu.Lock()
+			postedPaths = append(postedPaths, path)
+			first := len(postedPaths) == 1
+			mu.Unlock()
+			if first {
+				<-committed
+			}
+			return nil
+		},
+		getFunc: func(path string, resp interface{}) error {
+			return json.Unmarshal([]byte(`{"app_url":"https://preview.example.com"}`), resp)
+		},
+	}
+	deploys := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(postedPaths)
+	}
+
+	flags := deployCmdFlags{dir: "dist", app: "my-app", revisionName: "preview", gitMetadata: true}
+	d, err := resolveDeployment(flags)
+	require.NoError(t, err)
+	first, err := git.HeadSHA(".")
+	require.NoError(t, err)
+	watcher := &fileWatcher{roots: []string{"dist"}, interval: 10 * time.Millisecond, debounce: 20 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- watchDeployment(ctx, client, flags, d, watcher)
+	}()
+	require.Eventually(t, func() bool { return deploys() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	writeTestTree(t, repo, map[string]string{"dist/app.js": "console.log('hi')"})
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "Add script"},
+	} {
+		_, err := git.Run(repo, args...)
+		require.NoError(t, err)
+	}
+	second, err := git.HeadSHA(".")
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+	close(committed)
+
+	require.Eventually(t, func() bool { return deploys() == 2 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Contains(t, postedPaths[0], "revision="+first)
+	assert.Contains(t, postedPaths[0], "commit_message=Add+site")
+	assert.Contains(t, postedPaths[1], "revision="+second)
+	assert.Contains(t, postedPaths[1], "commit_message=Add+script")
+}
@@ -6,25 +6,103 @@ import (
 	"os"
 )
 
+// DefaultConfigPath is the runtime configuration file used when --config is not given
+const DefaultConfigPath = "runtime.config.json"
+
 // RuntimeConfig represents the structure of the runtime configuration file
 type RuntimeConfig struct {
 	App string `json:"app"`
+	// Dir is the directory to deploy, e.g. "dist"
+	Dir string `json:"dir,omitempty"`
+	// Build is the command run before the deploy directory is bundled, e.g. "npm run build"
+	Build string `json:"build,omitempty"`
+	// MaxSize is the largest bundle that may be deployed, e.g. "25MB"
+	MaxSize string `json:"maxSize,omitempty"`
+	// MaxFileSize is the largest single file that may be deployed, e.g. "5MB"
+	MaxFileSize string `json:"maxFileSize,omitempty"`
+	// Routes redirect and rewrite requests, e.g. [{"from": "/blog/*", "to": "/news/:splat", "status": 301}]
+	Routes []RouteConfig `json:"routes,omitempty"`
+	// Headers are added to the responses of matching requests, e.g.
+	// [{"path": "/assets/*", "headers": {"Cache-Control": "public, max-age=31536000, immutable"}}]
+	Headers []HeadersConfig `json:"headers,omitempty"`
+	// SPAFallback serves index.html for routes that do not match a file
+	SPAFallback bool `json:"spaFallback,omitempty"`
+	// SecretScan configures the scan for secrets that runs before a bundle is deployed
+	SecretScan SecretScanConfig `json:"secretScan,omitzero"`
+}
+
+// RouteConfig redirects or rewrites requests matching From to To
+type RouteConfig struct {
+	// From is a path, where ":name" matches one path element and a trailing "*" matches the rest
+	From string `json:"from"`
+	// To is a path or URL, where ":name" and ":splat" are replaced with the matched values
+	To string `json:"to"`
+	// Status is 200 to rewrite, 404 to serve a not found page, or a redirect status. Defaults to 301
+	Status int `json:"status,omitempty"`
+	// Force applies the route even when a file matches the request
+	Force bool `json:"force,omitempty"`
+}
+
+// HeadersConfig adds headers to the responses of requests matching Path
+type HeadersConfig struct {
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+}
+
+// SecretScanConfig configures the scan for secrets that runs before a bundle is deployed
+type SecretScanConfig struct {
+	// Ignore suppresses findings in files that are known not to hold secrets
+	Ignore []SecretScanIgnore `json:"ignore,omitempty"`
+}
+
+// SecretScanIgnore suppresses findings in the bundle files matching Path
+type SecretScanIgnore struct {
+	// Path is a glob of paths in the bundle, e.g. "assets/*.map" or "**/fixtures/**"
+	Path string `json:"path"`
+	// Rule limits the suppression to one kind of secret, e.g. "aws-access-key-id"
+	Rule string `json:"rule,omitempty"`
 }
 
 // ReadRuntimeConfig reads and parses a runtime configuration file
 func ReadRuntimeConfig(configPath string) (string, error) {
+	config, err := ParseRuntimeConfig(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	return config.App, nil
+}
+
+// ParseRuntimeConfig reads and parses a runtime configuration file, returning all of its settings
+func ParseRuntimeConfig(configPath string) (RuntimeConfig, error) {
 	configBytes, err := os.ReadFile(configPath)
 	if err != nil {
-		return "", fmt.Errorf("error reading config file '%s': %w", configPath, err)
+		return RuntimeConfig{}, fmt.Errorf("error reading config file '%s': %w", configPath, err)
 	}
 
 	var config RuntimeConfig
 	err = json.Unmarshal(configBytes, &config)
 	if err != nil {
-		return "", fmt.Errorf("error parsing config file '%s': %w", configPath, err)
+		return RuntimeConfig{}, fmt.Errorf("error parsing config file '%s': %w", configPath, err)
 	}
 
-	return config.App, nil
+	return config, nil
+}
+
+// LoadRuntimeConfig loads the runtime configuration using the same lookup as ResolveAppName:
+// 1. configPath (--config) if provided
+// 2. runtime.config.json in current directory if it exists
+// Returns an empty configuration if neither exists
+func LoadRuntimeConfig(configPath string) (RuntimeConfig, error) {
+	if configPath != "" {
+		return ParseRuntimeConfig(configPath)
+	}
+
+	if _, err := os.Stat(DefaultConfigPath); err == nil {
+		return ParseRuntimeConfig(DefaultConfigPath)
+	}
+
+	return RuntimeConfig{}, nil
 }
 
 // ResolveAppName resolves the app ID using the priority order:
@@ -44,8 +122,8 @@ func ResolveAppName(appFlag, configPath string) (string, error) {
 	}
 
 	// Priority 3: Try default runtime.config.json
-	if _, err := os.Stat("runtime.config.json"); err == nil {
-		appName, err := ReadRuntimeConfig("runtime.config.json")
+	if _, err := os.Stat(DefaultConfigPath); err == nil {
+		appName, err := ReadRuntimeConfig(DefaultConfigPath)
 		if err != nil {
 			return "", fmt.Errorf("found runtime.config.json but failed to read it: %v", err)
 		}
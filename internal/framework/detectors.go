@@ -0,0 +1,100 @@
+package framework
+
+import (
+	"strings"
+)
+
+var (
+	nextConfigFiles      = []string{"next.config.js", "next.config.mjs", "next.config.cjs", "next.config.ts"}
+	svelteKitConfigFiles = []string{"svelte.config.js", "svelte.config.mjs", "svelte.config.cjs", "svelte.config.ts"}
+	astroConfigFiles     = []string{"astro.config.mjs", "astro.config.js", "astro.config.cjs", "astro.config.ts", "astro.config.mts"}
+	viteConfigFiles      = []string{"vite.config.js", "vite.config.mjs", "vite.config.cjs", "vite.config.ts", "vite.config.mts", "vite.config.cts"}
+
+	outputPattern  = configPattern("output")
+	distDirPattern = configPattern("distDir")
+	pagesPattern   = configPattern("pages")
+	outDirPattern  = configPattern("outDir")
+)
+
+// detectNextExport recognizes Next.js projects configured for static export.
+// Projects that need a Next.js server are not recognized.
+func detectNextExport(p *Project) (Framework, bool) {
+	if !p.PackageJSON.HasDependency("next") {
+		return Framework{}, false
+	}
+
+	config := p.ReadFile(p.FindFile(nextConfigFiles...))
+	exported := configString(config, outputPattern) == "export"
+	for _, script := range p.PackageJSON.Scripts {
+		if strings.Contains(script, "next export") {
+			exported = true
+		}
+	}
+	if !exported {
+		return Framework{}, false
+	}
+
+	outputDir := configString(config, distDirPattern)
+	if outputDir == "" {
+		outputDir = "out"
+	}
+	return Framework{Name: "Next.js (static export)", OutputDir: outputDir, BuildCommand: p.BuildCommand()}, true
+}
+
+// detectSvelteKitStatic recognizes SvelteKit projects using adapter-static.
+func detectSvelteKitStatic(p *Project) (Framework, bool) {
+	if !p.PackageJSON.HasDependency("@sveltejs/kit") || !p.PackageJSON.HasDependency("@sveltejs/adapter-static") {
+		return Framework{}, false
+	}
+
+	outputDir := configString(p.ReadFile(p.FindFile(svelteKitConfigFiles...)), pagesPattern)
+	if outputDir == "" {
+		outputDir = "build"
+	}
+	return Framework{Name: "SvelteKit (static)", OutputDir: outputDir, BuildCommand: p.BuildCommand()}, true
+}
+
+// detectAstro recognizes Astro projects.
+func detectAstro(p *Project) (Framework, bool) {
+	configFile := p.FindFile(astroConfigFiles...)
+	if !p.PackageJSON.HasDependency("astro") && configFile == "" {
+		return Framework{}, false
+	}
+
+	outputDir := configString(p.ReadFile(configFile), outDirPattern)
+	if outputDir == "" {
+		outputDir = "dist"
+	}
+	return Framework{Name: "Astro", OutputDir: outputDir, BuildCommand: p.BuildCommand()}, true
+}
+
+// detectCreateReactApp recognizes Create React App projects.
+func detectCreateReactApp(p *Project) (Framework, bool) {
+	if !p.PackageJSON.HasDependency("react-scripts") {
+		return Framework{}, false
+	}
+	return Framework{Name: "Create React App", OutputDir: "build", BuildCommand: p.BuildCommand()}, true
+}
+
+// detectVite recognizes Vite projects.
+func detectVite(p *Project) (Framework, bool) {
+	configFile := p.FindFile(viteConfigFiles...)
+	if !p.PackageJSON.HasDependency("vite") && configFile == "" {
+		return Framework{}, false
+	}
+
+	outputDir := configString(p.ReadFile(configFile), outDirPattern)
+	if outputDir == "" {
+		outputDir = "dist"
+	}
+	return Framework{Name: "Vite", OutputDir: outputDir, BuildCommand: p.BuildCommand()}, true
+}
+
+// detectStatic recognizes plain static sites with an index.html in the project root.
+// They are deployed as they are, without a build step.
+func detectStatic(p *Project) (Framework, bool) {
+	if !p.Exists("index.html") {
+		return Framework{}, false
+	}
+	return Framework{Name: "Static HTML", OutputDir: "."}, true
+}
@@ -0,0 +1,174 @@
+// Package framework detects common frontend frameworks and infers how to build
+// and deploy them.
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Framework describes a detected project and how to deploy it
+type Framework struct {
+	// Name is the human readable name of the framework, e.g. "Vite"
+	Name string
+	// OutputDir is the directory containing the build output, relative to the project root
+	OutputDir string
+	// BuildCommand produces OutputDir. It is empty if the project has no build step
+	BuildCommand string
+}
+
+// PackageJSON is the subset of package.json used for detection
+type PackageJSON struct {
+	Scripts         map[string]string `json:"scripts"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+// HasDependency reports whether name is a dependency or dev dependency
+func (p *PackageJSON) HasDependency(name string) bool {
+	if p == nil {
+		return false
+	}
+	_, dep := p.Dependencies[name]
+	_, devDep := p.DevDependencies[name]
+	return dep || devDep
+}
+
+// Project is the project being inspected by a Detector
+type Project struct {
+	// Root is the project directory
+	Root string
+	// PackageJSON is the parsed package.json, or nil if the project does not have one
+	PackageJSON *PackageJSON
+}
+
+// Exists reports whether the file at the slash-separated path relative to the project root exists
+func (p *Project) Exists(name string) bool {
+	_, err := os.Stat(filepath.Join(p.Root, filepath.FromSlash(name)))
+	return err == nil
+}
+
+// FindFile returns the first of the given files that exists in the project, or "" if none do
+func (p *Project) FindFile(names ...string) string {
+	for _, name := range names {
+		if p.Exists(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// ReadFile returns the content of the slash-separated path relative to the project root, or "" if it cannot be read
+func (p *Project) ReadFile(name string) string {
+	data, err := os.ReadFile(filepath.Join(p.Root, filepath.FromSlash(name)))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// BuildCommand returns the command that runs the "build" script with the project's package manager,
+// or "" if there is no build script
+func (p *Project) BuildCommand() string {
+	if p.PackageJSON == nil || p.PackageJSON.Scripts["build"] == "" {
+		return ""
+	}
+
+	packageManager := "npm"
+	switch {
+	case p.Exists("pnpm-lock.yaml"):
+		packageManager = "pnpm"
+	case p.Exists("yarn.lock"):
+		packageManager = "yarn"
+	case p.Exists("bun.lockb"), p.Exists("bun.lock"):
+		packageManager = "bun"
+	}
+	return packageManager + " run build"
+}
+
+// Detector recognizes a framework in a project
+type Detector interface {
+	// Detect returns the framework and true if the project uses it
+	Detect(p *Project) (Framework, bool)
+}
+
+// DetectorFunc adapts a function to the Detector interface
+type DetectorFunc func(p *Project) (Framework, bool)
+
+// Detect calls f(p)
+func (f DetectorFunc) Detect(p *Project) (Framework, bool) {
+	return f(p)
+}
+
+// Registry holds detectors in priority order
+type Registry struct {
+	detectors []Detector
+}
+
+// NewRegistry creates a registry with the given detectors, highest priority first
+func NewRegistry(detectors ...Detector) *Registry {
+	return &Registry{detectors: detectors}
+}
+
+// Register adds a detector with a lower priority than the ones already registered
+func (r *Registry) Register(d Detector) {
+	r.detectors = append(r.detectors, d)
+}
+
+// Detect inspects the project in root and returns the first framework recognized by a detector.
+// It returns nil if no detector recognizes the project.
+func (r *Registry) Detect(root string) (*Framework, error) {
+	project := &Project{Root: root}
+
+	data, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if err == nil {
+		var pkg PackageJSON
+		err = json.Unmarshal(data, &pkg)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing '%s': %w", filepath.Join(root, "package.json"), err)
+		}
+		project.PackageJSON = &pkg
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading '%s': %w", filepath.Join(root, "package.json"), err)
+	}
+
+	for _, d := range r.detectors {
+		if fw, ok := d.Detect(project); ok {
+			return &fw, nil
+		}
+	}
+	return nil, nil
+}
+
+// DefaultRegistry holds the built-in detectors
+var DefaultRegistry = NewRegistry(
+	DetectorFunc(detectNextExport),
+	DetectorFunc(detectSvelteKitStatic),
+	DetectorFunc(detectAstro),
+	DetectorFunc(detectCreateReactApp),
+	DetectorFunc(detectVite),
+	DetectorFunc(detectStatic),
+)
+
+// Detect inspects the project in root using the default registry
+func Detect(root string) (*Framework, error) {
+	return DefaultRegistry.Detect(root)
+}
+
+// configPattern returns the pattern configString uses to find the quoted value
+// assigned to key in a JavaScript config file
+func configPattern(key string) *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(key) + `\s*:\s*['"\x60]([^'"\x60]+)['"\x60]`)
+}
+
+// configString returns the first quoted value matched by a configPattern in a JavaScript config
+// file, e.g. outDir: 'public' returns "public"
+func configString(content string, pattern *regexp.Regexp) string {
+	if m := pattern.FindStringSubmatch(content); m != nil {
+		return m[1]
+	}
+	return ""
+}
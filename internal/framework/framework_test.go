@@ -0,0 +1,65 @@
+package framework
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    *Framework
+	}{
+		{"vite", &Framework{Name: "Vite", OutputDir: "public-build", BuildCommand: "pnpm run build"}},
+		{"next-export", &Framework{Name: "Next.js (static export)", OutputDir: "out", BuildCommand: "npm run build"}},
+		{"next-server", nil},
+		{"create-react-app", &Framework{Name: "Create React App", OutputDir: "build", BuildCommand: "yarn run build"}},
+		{"astro", &Framework{Name: "Astro", OutputDir: "dist", BuildCommand: "npm run build"}},
+		{"sveltekit-static", &Framework{Name: "SvelteKit (static)", OutputDir: "site", BuildCommand: "npm run build"}},
+		{"static", &Framework{Name: "Static HTML", OutputDir: "."}},
+		{"empty", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := Detect(filepath.Join("testdata", tt.fixture))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetect_InvalidPackageJSON(t *testing.T) {
+	_, err := Detect(filepath.Join("testdata", "invalid"))
+	require.ErrorContains(t, err, "error parsing")
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(DetectorFunc(func(p *Project) (Framework, bool) {
+		return Framework{}, false
+	}))
+	registry.Register(DetectorFunc(func(p *Project) (Framework, bool) {
+		if p.Exists("index.html") {
+			return Framework{Name: "Custom", OutputDir: "."}, true
+		}
+		return Framework{}, false
+	}))
+
+	got, err := registry.Detect(filepath.Join("testdata", "static"))
+	require.NoError(t, err)
+	assert.Equal(t, &Framework{Name: "Custom", OutputDir: "."}, got)
+
+	got, err = registry.Detect(filepath.Join("testdata", "empty"))
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestConfigString(t *testing.T) {
+	assert.Equal(t, "public", configString(`build: { outDir: 'public' }`, outDirPattern))
+	assert.Equal(t, "export", configString(`output:"export"`, outputPattern))
+	assert.Equal(t, "", configString(`build: {}`, outDirPattern))
+}
@@ -0,0 +1,111 @@
+package git
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Export writes the tree of the commit that rev refers to into dest, which must be an existing
+// directory. The whole tree of the repository containing dir is exported, whatever subdirectory
+// dir is.
+func Export(dir, rev, dest string) error {
+	top, err := Run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "archive", "--format=tar", rev)
+	cmd.Dir = top
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("git archive: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git archive: %w", err)
+	}
+
+	extractErr := extractTar(stdout, dest)
+	// Drain the rest of the archive so that git does not block if extraction stopped early
+	io.Copy(io.Discard, stdout)
+	waitErr := cmd.Wait()
+	if waitErr != nil {
+		if stderr.Len() > 0 {
+			return fmt.Errorf("git archive %s: %s", rev, strings.TrimSpace(stderr.String()))
+		}
+		return fmt.Errorf("git archive %s: %w", rev, waitErr)
+	}
+	if extractErr != nil {
+		return fmt.Errorf("error extracting %s: %w", rev, extractErr)
+	}
+	return nil
+}
+
+// extractTar writes the directories, regular files and symbolic links of a tar stream into dest
+func extractTar(r io.Reader, dest string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("archive entry '%s' is outside of the archive root", header.Name)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(name))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr, header.FileInfo().Mode()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		default:
+			// git writes a global header holding the commit ID, which has no file to extract
+		}
+	}
+}
+
+func writeFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	perm := os.FileMode(0644)
+	if mode&0111 != 0 {
+		perm = 0755
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+// Package git runs git commands against a local repository.
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Run runs git with the given arguments in dir and returns its output without surrounding whitespace
+func Run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// CurrentBranch returns the branch checked out in the repository containing dir.
+// It returns an empty string if HEAD is detached.
+func CurrentBranch(dir string) (string, error) {
+	branch, err := Run(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if branch == "HEAD" {
+		return "", nil
+	}
+	return branch, nil
+}
+
+// RevParse returns the full commit SHA that rev refers to
+func RevParse(dir, rev string) (string, error) {
+	return Run(dir, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}")
+}
+
+// HeadSHA returns the SHA of the commit checked out in the repository containing dir
+func HeadSHA(dir string) (string, error) {
+	return RevParse(dir, "HEAD")
+}
+
+// IsDirty reports whether the working tree of the repository containing dir has uncommitted
+// changes, including untracked files that are not ignored
+func IsDirty(dir string) (bool, error) {
+	status, err := Run(dir, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return status != "", nil
+}
+
+// CommitSubject returns the first line of the message of the commit that rev refers to
+func CommitSubject(dir, rev string) (string, error) {
+	return Run(dir, "log", "-1", "--format=%s", rev)
+}
+
+// Prefix returns the path of dir relative to the top level of its repository, using slashes and
+// ending with a slash, or an empty string at the top level
+func Prefix(dir string) (string, error) {
+	return Run(dir, "rev-parse", "--show-prefix")
+}
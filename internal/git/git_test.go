@@ -0,0 +1,118 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initRepo creates a repository with a single commit on branch and returns its directory.
+func initRepo(t *testing.T, branch string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", branch},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty", "-m", "initial"},
+	} {
+		_, err := Run(dir, args...)
+		require.NoError(t, err)
+	}
+	return dir
+}
+
+func TestCurrentBranch(t *testing.T) {
+	dir := initRepo(t, "feature/login")
+
+	branch, err := CurrentBranch(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "feature/login", branch)
+}
+
+func TestCurrentBranch_Detached(t *testing.T) {
+	dir := initRepo(t, "main")
+	_, err := Run(dir, "checkout", "--quiet", "--detach")
+	require.NoError(t, err)
+
+	branch, err := CurrentBranch(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "", branch)
+}
+
+func TestRun_Error(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	_, err := Run(t.TempDir(), "rev-parse", "HEAD")
+	require.ErrorContains(t, err, "git rev-parse HEAD")
+}
+
+func TestHeadSHA(t *testing.T) {
+	dir := initRepo(t, "main")
+
+	sha, err := HeadSHA(dir)
+	require.NoError(t, err)
+	assert.Len(t, sha, 40)
+
+	resolved, err := RevParse(dir, "main")
+	require.NoError(t, err)
+	assert.Equal(t, sha, resolved)
+}
+
+func TestRevParse_Unknown(t *testing.T) {
+	dir := initRepo(t, "main")
+
+	_, err := RevParse(dir, "does-not-exist")
+	require.Error(t, err)
+}
+
+func TestIsDirty(t *testing.T) {
+	dir := initRepo(t, "main")
+
+	dirty, err := IsDirty(dir)
+	require.NoError(t, err)
+	assert.False(t, dirty)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0644))
+	dirty, err = IsDirty(dir)
+	require.NoError(t, err)
+	assert.True(t, dirty)
+}
+
+func TestCommitSubject(t *testing.T) {
+	dir := initRepo(t, "main")
+
+	subject, err := CommitSubject(dir, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, "initial", subject)
+}
+
+func TestExport(t *testing.T) {
+	dir := initRepo(t, "main")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "file.txt"), []byte("committed"), 0644))
+	_, err := Run(dir, "add", "-A")
+	require.NoError(t, err)
+	_, err = Run(dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "add file")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "file.txt"), []byte("local"), 0644))
+
+	dest := t.TempDir()
+	require.NoError(t, Export(filepath.Join(dir, "sub"), "HEAD", dest))
+
+	content, err := os.ReadFile(filepath.Join(dest, "sub", "file.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "committed", string(content))
+
+	prefix, err := Prefix(filepath.Join(dir, "sub"))
+	require.NoError(t, err)
+	assert.Equal(t, "sub/", prefix)
+}
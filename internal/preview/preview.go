@@ -0,0 +1,103 @@
+// Package preview defines how preview revisions are named.
+//
+// Preview revisions are named after the pull request or branch they were deployed from, e.g.
+// "preview-pr-42" or "preview-feature-login". Names only contain lowercase letters, digits and
+// hyphens, and are at most MaxNameLength characters long so they can be used in URLs.
+package preview
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Prefix starts the name of every preview revision
+	Prefix = "preview-"
+	// MaxNameLength is the maximum length of a preview revision name, the length of a DNS label
+	MaxNameLength = 63
+
+	pullRequestPrefix = Prefix + "pr-"
+	// hashLength is the number of hex characters appended to names that had to be truncated
+	hashLength = 6
+)
+
+// ForPullRequest returns the revision name for a pull request
+func ForPullRequest(number int) string {
+	return pullRequestPrefix + strconv.Itoa(number)
+}
+
+// ForBranch returns the revision name for a branch. Branch names that are too long are truncated
+// and suffixed with a hash of the full name, so that distinct branches keep distinct names.
+// Branch names starting with "pr-" are suffixed with the hash too, so that they never take the
+// name of a pull request preview.
+func ForBranch(branch string) (string, error) {
+	sanitized := Sanitize(branch)
+	if sanitized == "" {
+		return "", fmt.Errorf("branch name '%s' does not contain any characters usable in a revision name", branch)
+	}
+
+	name := Prefix + sanitized
+	if len(name) <= MaxNameLength && !strings.HasPrefix(name, pullRequestPrefix) {
+		return name, nil
+	}
+
+	sum := sha256.Sum256([]byte(branch))
+	suffix := "-" + hex.EncodeToString(sum[:])[:hashLength]
+	if len(name) > MaxNameLength-len(suffix) {
+		name = strings.TrimRight(name[:MaxNameLength-len(suffix)], "-")
+	}
+	return name + suffix, nil
+}
+
+// Sanitize lowercases name and replaces every run of characters other than letters and digits
+// with a single hyphen, trimming hyphens from both ends
+func Sanitize(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+		} else {
+			pendingHyphen = true
+		}
+	}
+	return b.String()
+}
+
+// IsPreview reports whether name is the name of a preview revision
+func IsPreview(name string) bool {
+	return strings.HasPrefix(name, Prefix)
+}
+
+// PullRequestNumber returns the pull request a preview revision was deployed from, if any
+func PullRequestNumber(name string) (int, bool) {
+	if !strings.HasPrefix(name, pullRequestPrefix) {
+		return 0, false
+	}
+	number, err := strconv.Atoi(strings.TrimPrefix(name, pullRequestPrefix))
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+	return number, true
+}
+
+// PullRequestFromRef returns the pull request number from a ref such as "refs/pull/42/merge",
+// as set in GITHUB_REF for pull request workflows
+func PullRequestFromRef(ref string) (int, bool) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 4 || parts[0] != "refs" || parts[1] != "pull" {
+		return 0, false
+	}
+	number, err := strconv.Atoi(parts[2])
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+	return number, true
+}
@@ -0,0 +1,132 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"main", "main"},
+		{"feature/Login-Page", "feature-login-page"},
+		{"fix__double--separators", "fix-double-separators"},
+		{"--leading/and/trailing--", "leading-and-trailing"},
+		{"dependabot/npm_and_yarn/vite-5.0.1", "dependabot-npm-and-yarn-vite-5-0-1"},
+		{"héllo wörld", "h-llo-w-rld"},
+		{"///", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Sanitize(tt.name))
+		})
+	}
+}
+
+func TestForPullRequest(t *testing.T) {
+	assert.Equal(t, "preview-pr-42", ForPullRequest(42))
+}
+
+func TestForBranch(t *testing.T) {
+	name, err := ForBranch("feature/Login")
+	require.NoError(t, err)
+	assert.Equal(t, "preview-feature-login", name)
+}
+
+func TestForBranch_Unusable(t *testing.T) {
+	_, err := ForBranch("///")
+	require.ErrorContains(t, err, "does not contain any characters")
+}
+
+func TestForBranch_Truncated(t *testing.T) {
+	long := "feature/" + strings.Repeat("a", 80)
+	other := "feature/" + strings.Repeat("a", 79) + "b"
+
+	name, err := ForBranch(long)
+	require.NoError(t, err)
+	otherName, err := ForBranch(other)
+	require.NoError(t, err)
+
+	assert.LessOrEqual(t, len(name), MaxNameLength)
+	assert.True(t, strings.HasPrefix(name, "preview-feature-aaaa"))
+	assert.NotEqual(t, name, otherName, "truncated names should stay distinct")
+
+	again, err := ForBranch(long)
+	require.NoError(t, err)
+	assert.Equal(t, name, again, "names should be stable")
+}
+
+func TestForBranch_PullRequestCollision(t *testing.T) {
+	for _, branch := range []string{"pr-42", "PR/42", "pr-042"} {
+		t.Run(branch, func(t *testing.T) {
+			name, err := ForBranch(branch)
+			require.NoError(t, err)
+
+			assert.NotEqual(t, ForPullRequest(42), name)
+			assert.Regexp(t, `^preview-pr-0?42-[0-9a-f]{6}$`, name)
+			_, ok := PullRequestNumber(name)
+			assert.False(t, ok, "branch previews should not be taken for pull request previews")
+		})
+	}
+
+	name, err := ForBranch("pr-fix")
+	require.NoError(t, err)
+	assert.NotEqual(t, "preview-pr-fix", name)
+}
+
+func TestIsPreview(t *testing.T) {
+	assert.True(t, IsPreview("preview-pr-1"))
+	assert.True(t, IsPreview("preview-main"))
+	assert.False(t, IsPreview("staging"))
+	assert.False(t, IsPreview("pr-1"))
+}
+
+func TestPullRequestNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   int
+		wantOK bool
+	}{
+		{"preview-pr-42", 42, true},
+		{"preview-pr-0", 0, false},
+		{"preview-pr-feature", 0, false},
+		{"preview-main", 0, false},
+		{"staging", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := PullRequestNumber(tt.name)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPullRequestFromRef(t *testing.T) {
+	tests := []struct {
+		ref    string
+		want   int
+		wantOK bool
+	}{
+		{"refs/pull/42/merge", 42, true},
+		{"refs/pull/7/head", 7, true},
+		{"refs/heads/main", 0, false},
+		{"refs/pull/abc/merge", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, ok := PullRequestFromRef(tt.ref)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PayloadType is the DSSE payload type of in-toto statements
+const PayloadType = "application/vnd.in-toto+json"
+
+// Envelope is a DSSE envelope holding a signed statement
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a signature of a DSSE envelope
+type Signature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   string `json:"sig"`
+}
+
+// Sign encodes the statement and signs it with key
+func Sign(statement Statement, key ed25519.PrivateKey) (Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("error encoding provenance statement: %w", err)
+	}
+
+	keyID, err := KeyID(key.Public().(ed25519.PublicKey))
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	sig := ed25519.Sign(key, pae(PayloadType, payload))
+	return Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{{KeyID: keyID, Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}, nil
+}
+
+// Verify checks that the envelope was signed with key and returns the statement it holds
+func Verify(envelope Envelope, key ed25519.PublicKey) (Statement, error) {
+	if envelope.PayloadType != PayloadType {
+		return Statement{}, fmt.Errorf("unsupported payload type '%s'", envelope.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return Statement{}, fmt.Errorf("error decoding payload: %w", err)
+	}
+
+	verified := false
+	for _, signature := range envelope.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(signature.Sig)
+		if err != nil {
+			continue
+		}
+		if ed25519.Verify(key, pae(envelope.PayloadType, payload), sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return Statement{}, errors.New("no signature matches the public key")
+	}
+
+	statement := Statement{}
+	err = json.Unmarshal(payload, &statement)
+	if err != nil {
+		return Statement{}, fmt.Errorf("error parsing provenance statement: %w", err)
+	}
+	if statement.Type != StatementType || statement.PredicateType != PredicateType {
+		return Statement{}, fmt.Errorf("unsupported statement type '%s' with predicate '%s'", statement.Type, statement.PredicateType)
+	}
+	return statement, nil
+}
+
+// KeyID returns the hex SHA-256 digest of the PKIX encoding of a public key
+func KeyID(key ed25519.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", fmt.Errorf("error encoding public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// pae returns the DSSE pre-authentication encoding of a payload, which is what is signed
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
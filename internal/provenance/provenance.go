@@ -0,0 +1,163 @@
+// Package provenance describes how a bundle was built as an in-toto statement with a SLSA
+// provenance predicate, and signs and verifies it in a DSSE envelope.
+//
+// See https://in-toto.io/Statement/v1, https://slsa.dev/provenance/v1 and
+// https://github.com/secure-systems-lab/dsse
+package provenance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	// StatementType is the type of every in-toto statement
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PredicateType is the type of SLSA provenance predicates
+	PredicateType = "https://slsa.dev/provenance/v1"
+	// BuildType identifies how gh runtime builds bundles
+	BuildType = "https://github.com/github/gh-runtime-cli/bundle/v1"
+)
+
+// Statement is an in-toto statement about the bundles in its subject
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Subject is an artifact a statement is about, identified by its digests
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate is a SLSA provenance predicate
+type Predicate struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition describes the inputs of a build
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   map[string]string    `json:"externalParameters"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// ResourceDescriptor identifies a source a build used
+type ResourceDescriptor struct {
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest"`
+}
+
+// RunDetails describes who ran a build and when
+type RunDetails struct {
+	Builder  Builder  `json:"builder"`
+	Metadata Metadata `json:"metadata"`
+}
+
+// Builder identifies what ran a build
+type Builder struct {
+	ID string `json:"id"`
+}
+
+// Metadata holds the timestamps and identifier of a build
+type Metadata struct {
+	InvocationID string    `json:"invocationId,omitempty"`
+	StartedOn    time.Time `json:"startedOn"`
+	FinishedOn   time.Time `json:"finishedOn"`
+}
+
+// Build holds what is known about the build of a bundle
+type Build struct {
+	// BundleName is the name of the bundle in the statement subject
+	BundleName string
+	// BundleDigest is the hex SHA-256 digest of the bundle
+	BundleDigest string
+	// Commit is the git commit the bundle was built from, if known
+	Commit string
+	// Repository is the URL of the repository the commit belongs to, if known
+	Repository string
+	// Parameters are the settings of the build, such as its command and directory
+	Parameters   map[string]string
+	Builder      string
+	InvocationID string
+	StartedOn    time.Time
+	FinishedOn   time.Time
+}
+
+// NewStatement returns the provenance statement of a build
+func NewStatement(build Build) Statement {
+	parameters := build.Parameters
+	if parameters == nil {
+		parameters = map[string]string{}
+	}
+
+	definition := BuildDefinition{BuildType: BuildType, ExternalParameters: parameters}
+	if build.Commit != "" {
+		dependency := ResourceDescriptor{Digest: map[string]string{"gitCommit": build.Commit}}
+		if build.Repository != "" {
+			dependency.URI = "git+" + build.Repository + "@" + build.Commit
+		}
+		definition.ResolvedDependencies = []ResourceDescriptor{dependency}
+	}
+
+	return Statement{
+		Type:          StatementType,
+		Subject:       []Subject{{Name: build.BundleName, Digest: map[string]string{"sha256": build.BundleDigest}}},
+		PredicateType: PredicateType,
+		Predicate: Predicate{
+			BuildDefinition: definition,
+			RunDetails: RunDetails{
+				Builder: Builder{ID: build.Builder},
+				Metadata: Metadata{
+					InvocationID: build.InvocationID,
+					StartedOn:    build.StartedOn.UTC(),
+					FinishedOn:   build.FinishedOn.UTC(),
+				},
+			},
+		},
+	}
+}
+
+// Commit returns the git commit the statement says the bundle was built from, if any
+func (s Statement) Commit() string {
+	for _, dependency := range s.Predicate.BuildDefinition.ResolvedDependencies {
+		if commit := dependency.Digest["gitCommit"]; commit != "" {
+			return commit
+		}
+	}
+	return ""
+}
+
+// CoversDigest reports whether the statement is about an artifact with the given hex SHA-256 digest
+func (s Statement) CoversDigest(digest string) bool {
+	for _, subject := range s.Subject {
+		if subject.Digest["sha256"] == digest {
+			return true
+		}
+	}
+	return false
+}
+
+// FileDigest returns the hex SHA-256 digest of a file
+func FileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", fmt.Errorf("error reading '%s': %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,129 @@
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBuild() Build {
+	return Build{
+		BundleName:   "bundle.zip",
+		BundleDigest: "586a866f990ab55e36decfffc2011f172e4452d5141c939a5436baffba11111d",
+		Commit:       "1111111111111111111111111111111111111111",
+		Repository:   "https://github.com/octo/site",
+		Parameters:   map[string]string{"buildCommand": "npm run build"},
+		Builder:      "https://github.com/octo/site/.github/workflows/deploy.yml@refs/heads/main",
+		StartedOn:    time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		FinishedOn:   time.Date(2024, 5, 1, 10, 1, 0, 0, time.UTC),
+	}
+}
+
+func TestNewStatement(t *testing.T) {
+	statement := NewStatement(testBuild())
+
+	assert.Equal(t, StatementType, statement.Type)
+	assert.Equal(t, PredicateType, statement.PredicateType)
+	assert.Equal(t, []Subject{{Name: "bundle.zip", Digest: map[string]string{"sha256": testBuild().BundleDigest}}}, statement.Subject)
+	assert.Equal(t, "1111111111111111111111111111111111111111", statement.Commit())
+	assert.Equal(t, "git+https://github.com/octo/site@1111111111111111111111111111111111111111", statement.Predicate.BuildDefinition.ResolvedDependencies[0].URI)
+	assert.True(t, statement.CoversDigest(testBuild().BundleDigest))
+	assert.False(t, statement.CoversDigest("00"))
+}
+
+func TestNewStatement_WithoutCommit(t *testing.T) {
+	build := testBuild()
+	build.Commit = ""
+
+	statement := NewStatement(build)
+	assert.Empty(t, statement.Predicate.BuildDefinition.ResolvedDependencies)
+	assert.Empty(t, statement.Commit())
+}
+
+func TestSignAndVerify(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	envelope, err := Sign(NewStatement(testBuild()), private)
+	require.NoError(t, err)
+	assert.Equal(t, PayloadType, envelope.PayloadType)
+	require.Len(t, envelope.Signatures, 1)
+	keyID, err := KeyID(public)
+	require.NoError(t, err)
+	assert.Equal(t, keyID, envelope.Signatures[0].KeyID)
+
+	statement, err := Verify(envelope, public)
+	require.NoError(t, err)
+	assert.Equal(t, NewStatement(testBuild()), statement)
+}
+
+func TestVerify_WrongKey(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	envelope, err := Sign(NewStatement(testBuild()), private)
+	require.NoError(t, err)
+
+	_, err = Verify(envelope, other)
+	require.ErrorContains(t, err, "no signature matches")
+}
+
+func TestVerify_TamperedPayload(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	envelope, err := Sign(NewStatement(testBuild()), private)
+	require.NoError(t, err)
+
+	tampered := NewStatement(testBuild())
+	tampered.Subject[0].Digest["sha256"] = "00"
+	payload, err := json.Marshal(tampered)
+	require.NoError(t, err)
+	envelope.Payload = base64.StdEncoding.EncodeToString(payload)
+
+	_, err = Verify(envelope, public)
+	require.ErrorContains(t, err, "no signature matches")
+}
+
+func TestParseKeys(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	parsedPrivate, err := ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	require.NoError(t, err)
+	assert.Equal(t, private, parsedPrivate)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+	parsedPublic, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	require.NoError(t, err)
+	assert.Equal(t, public, parsedPublic)
+
+	_, err = ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	require.ErrorContains(t, err, "expected a PEM encoded 'PRIVATE KEY'")
+	_, err = ParsePublicKey([]byte("not a key"))
+	require.ErrorContains(t, err, "expected a PEM encoded 'PUBLIC KEY'")
+}
+
+func TestFileDigest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0644))
+
+	digest, err := FileDigest(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", digest)
+}
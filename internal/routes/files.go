@@ -0,0 +1,104 @@
+package routes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// RedirectsFile is the name of the file in the deploy directory that
+	// lists redirect and rewrite rules
+	RedirectsFile = "_redirects"
+	// HeadersFile is the name of the file in the deploy directory that lists
+	// header rules
+	HeadersFile = "_headers"
+)
+
+// ParseRedirects reads rules in the _redirects format. Each line holds a
+// path, a target and an optional status, where a trailing "!" forces the
+// rule:
+//
+//	/blog/*     /news/:splat  301
+//	/app/*      /app.html     200
+//	/old        /new          302!
+func ParseRedirects(r io.Reader) ([]Rule, error) {
+	rules := []Rule{}
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected a path and a target", lineNumber)
+		}
+		if len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: unexpected '%s', conditions are not supported", lineNumber, fields[3])
+		}
+
+		rule := Rule{From: fields[0], To: fields[1], Status: DefaultStatus}
+		if len(fields) == 3 {
+			status := fields[2]
+			rule.Force = strings.HasSuffix(status, "!")
+			code, err := strconv.Atoi(strings.TrimSuffix(status, "!"))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid status '%s'", lineNumber, status)
+			}
+			rule.Status = code
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ParseHeaders reads rules in the _headers format. A path is followed by the
+// indented headers of its responses:
+//
+//	/assets/*
+//	  Cache-Control: public, max-age=31536000, immutable
+//	/*
+//	  X-Frame-Options: DENY
+//
+// Headers repeated for the same path are joined with ", ".
+func ParseHeaders(r io.Reader) ([]HeaderRule, error) {
+	rules := []HeaderRule{}
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			rules = append(rules, HeaderRule{Path: trimmed, Headers: map[string]string{}})
+			continue
+		}
+
+		if len(rules) == 0 {
+			return nil, fmt.Errorf("line %d: header before any path", lineNumber)
+		}
+		name, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected 'Name: value'", lineNumber)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		headers := rules[len(rules)-1].Headers
+		if existing, ok := headers[name]; ok {
+			value = existing + ", " + value
+		}
+		headers[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,240 @@
+// Package routes describes how requests to a deployed app are redirected,
+// rewritten and answered with custom headers, and matches requests against
+// those rules.
+//
+// Rules are read from the runtime config file and from the _redirects and
+// _headers files of the deploy directory, in the format popularized by Netlify.
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Version is the version of the serialized routes format
+	Version = 1
+	// MaxRules is the largest number of redirect and header rules an app may have
+	MaxRules = 1000
+	// DefaultStatus is the status of rules that do not set one
+	DefaultStatus = http.StatusMovedPermanently
+)
+
+// Config is the routing of an app
+type Config struct {
+	Version int `json:"version"`
+	// Rules redirect or rewrite requests. The first matching rule applies.
+	Rules []Rule `json:"rules,omitempty"`
+	// Headers are added to the responses of matching requests
+	Headers []HeaderRule `json:"headers,omitempty"`
+	// SPAFallback serves index.html for paths without an extension that do not
+	// match a file
+	SPAFallback bool `json:"spaFallback,omitempty"`
+}
+
+// Rule redirects or rewrites requests matching From to To.
+//
+// From is a path where ":name" matches a single element and a trailing "*"
+// matches the rest of the path. The matched values are substituted for
+// ":name" and ":splat" in To.
+type Rule struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Status is 200 to rewrite, 404 to serve To as the not found page, or a
+	// 3xx redirect status
+	Status int `json:"status"`
+	// Force applies the rule even when a file matches the request
+	Force bool `json:"force,omitempty"`
+}
+
+// HeaderRule adds headers to the responses of requests matching Path, which
+// uses the same patterns as Rule.From
+type HeaderRule struct {
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+}
+
+// IsEmpty reports whether c changes nothing about how an app is served
+func (c Config) IsEmpty() bool {
+	return len(c.Rules) == 0 && len(c.Headers) == 0 && !c.SPAFallback
+}
+
+// Merge adds the rules of other to c, so that rules of c take precedence. Since the first matching
+// route applies but later header rules override earlier ones, the routes of other are appended
+// and its header rules are prepended.
+func (c *Config) Merge(other Config) {
+	c.Rules = append(c.Rules, other.Rules...)
+	c.Headers = append(append([]HeaderRule{}, other.Headers...), c.Headers...)
+	c.SPAFallback = c.SPAFallback || other.SPAFallback
+}
+
+var (
+	placeholderPattern = regexp.MustCompile(`:([A-Za-z_][A-Za-z0-9_]*)`)
+	headerNamePattern  = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+)
+
+// Validate checks every rule and returns all problems found
+func (c Config) Validate() error {
+	problems := []error{}
+	if len(c.Rules)+len(c.Headers) > MaxRules {
+		problems = append(problems, fmt.Errorf("too many rules: %d, the limit is %d", len(c.Rules)+len(c.Headers), MaxRules))
+	}
+	for _, rule := range c.Rules {
+		if err := rule.validate(); err != nil {
+			problems = append(problems, fmt.Errorf("route '%s': %w", rule.From, err))
+		}
+	}
+	for _, rule := range c.Headers {
+		if err := rule.validate(); err != nil {
+			problems = append(problems, fmt.Errorf("headers for '%s': %w", rule.Path, err))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+func (r Rule) validate() error {
+	names, err := patternNames(r.From)
+	if err != nil {
+		return err
+	}
+
+	switch r.Status {
+	case http.StatusOK, http.StatusNotFound:
+		if !strings.HasPrefix(r.To, "/") {
+			return fmt.Errorf("target '%s' of a %d rule must be a path in the app", r.To, r.Status)
+		}
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		if !strings.HasPrefix(r.To, "/") && !strings.HasPrefix(r.To, "https://") && !strings.HasPrefix(r.To, "http://") {
+			return fmt.Errorf("target '%s' must be a path or an http(s) URL", r.To)
+		}
+	default:
+		return fmt.Errorf("unsupported status %d, must be 200, 301, 302, 303, 307, 308 or 404", r.Status)
+	}
+
+	for _, match := range placeholderPattern.FindAllStringSubmatch(r.To, -1) {
+		if !names[match[1]] {
+			return fmt.Errorf("target uses ':%s', which is not matched by '%s'", match[1], r.From)
+		}
+	}
+	return nil
+}
+
+func (h HeaderRule) validate() error {
+	_, err := patternNames(h.Path)
+	if err != nil {
+		return err
+	}
+	if len(h.Headers) == 0 {
+		return fmt.Errorf("no headers")
+	}
+	for name, value := range h.Headers {
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid header name '%s'", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("value of header '%s' contains a line break", name)
+		}
+	}
+	return nil
+}
+
+// patternNames checks a path pattern and returns the names it captures,
+// including "splat" for a trailing "*".
+func patternNames(pattern string) (map[string]bool, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("path must start with '/'")
+	}
+	if strings.ContainsAny(pattern, "?#") {
+		return nil, fmt.Errorf("path must not contain a query or fragment")
+	}
+
+	names := map[string]bool{}
+	elements := strings.Split(pattern[1:], "/")
+	for i, element := range elements {
+		switch {
+		case element == "*":
+			if i != len(elements)-1 {
+				return nil, fmt.Errorf("'*' is only allowed at the end of the path")
+			}
+			names["splat"] = true
+		case strings.Contains(element, "*"):
+			return nil, fmt.Errorf("'*' must be a whole path element")
+		case strings.HasPrefix(element, ":"):
+			name := element[1:]
+			if !placeholderPattern.MatchString(element) || placeholderPattern.FindString(element) != element {
+				return nil, fmt.Errorf("invalid placeholder '%s'", element)
+			}
+			if names[name] {
+				return nil, fmt.Errorf("placeholder '%s' is used more than once", element)
+			}
+			names[name] = true
+		}
+	}
+	return names, nil
+}
+
+// Route returns the first rule matching urlPath and its target with the
+// matched values substituted.
+func (c Config) Route(urlPath string) (Rule, string, bool) {
+	for _, rule := range c.Rules {
+		values, ok := match(rule.From, urlPath)
+		if !ok {
+			continue
+		}
+		target := placeholderPattern.ReplaceAllStringFunc(rule.To, func(placeholder string) string {
+			return values[placeholder[1:]]
+		})
+		return rule, target, true
+	}
+	return Rule{}, "", false
+}
+
+// HeadersFor returns the headers of every header rule matching urlPath. Later
+// rules override the headers of earlier ones.
+func (c Config) HeadersFor(urlPath string) http.Header {
+	headers := http.Header{}
+	for _, rule := range c.Headers {
+		if _, ok := match(rule.Path, urlPath); !ok {
+			continue
+		}
+		for name, value := range rule.Headers {
+			headers.Set(name, value)
+		}
+	}
+	return headers
+}
+
+// match matches urlPath against a pattern and returns the values of its
+// placeholders. A trailing "*" also matches the path without it, so "/blog/*"
+// matches "/blog".
+func match(pattern, urlPath string) (map[string]string, bool) {
+	patternElements := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	pathElements := strings.Split(strings.TrimPrefix(urlPath, "/"), "/")
+
+	values := map[string]string{}
+	for i, element := range patternElements {
+		if element == "*" {
+			if i < len(pathElements) {
+				values["splat"] = strings.Join(pathElements[i:], "/")
+			}
+			return values, true
+		}
+		if i >= len(pathElements) {
+			return nil, false
+		}
+		if strings.HasPrefix(element, ":") {
+			if pathElements[i] == "" {
+				return nil, false
+			}
+			values[element[1:]] = pathElements[i]
+			continue
+		}
+		if element != pathElements[i] {
+			return nil, false
+		}
+	}
+	return values, len(patternElements) == len(pathElements)
+}
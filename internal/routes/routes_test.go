@@ -0,0 +1,239 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoute(t *testing.T) {
+	config := Config{Rules: []Rule{
+		{From: "/blog/:year/:slug", To: "/posts/:slug?year=:year", Status: 301},
+		{From: "/docs/*", To: "https://docs.example.com/:splat", Status: 302},
+		{From: "/app/*", To: "/app/index.html", Status: 200},
+		{From: "/old", To: "/new", Status: 308, Force: true},
+	}}
+
+	tests := []struct {
+		path   string
+		target string
+		status int
+		ok     bool
+	}{
+		{path: "/blog/2024/hello", target: "/posts/hello?year=2024", status: 301, ok: true},
+		{path: "/blog/2024", ok: false},
+		{path: "/blog/2024/hello/more", ok: false},
+		{path: "/docs/guide/start", target: "https://docs.example.com/guide/start", status: 302, ok: true},
+		{path: "/docs", target: "https://docs.example.com/", status: 302, ok: true},
+		{path: "/documents", ok: false},
+		{path: "/app/settings/profile", target: "/app/index.html", status: 200, ok: true},
+		{path: "/old", target: "/new", status: 308, ok: true},
+		{path: "/old/page", ok: false},
+		{path: "/", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rule, target, ok := config.Route(tt.path)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.target, target)
+			assert.Equal(t, tt.status, rule.Status)
+		})
+	}
+}
+
+func TestHeadersFor(t *testing.T) {
+	config := Config{Headers: []HeaderRule{
+		{Path: "/*", Headers: map[string]string{"X-Frame-Options": "DENY", "Cache-Control": "no-cache"}},
+		{Path: "/assets/*", Headers: map[string]string{"Cache-Control": "public, max-age=31536000, immutable"}},
+	}}
+
+	assert.Equal(t, http.Header{
+		"X-Frame-Options": {"DENY"},
+		"Cache-Control":   {"public, max-age=31536000, immutable"},
+	}, config.HeadersFor("/assets/app.js"))
+	assert.Equal(t, http.Header{
+		"X-Frame-Options": {"DENY"},
+		"Cache-Control":   {"no-cache"},
+	}, config.HeadersFor("/index.html"))
+}
+
+func TestMerge(t *testing.T) {
+	config := Config{
+		Rules:   []Rule{{From: "/old", To: "/files", Status: http.StatusFound}},
+		Headers: []HeaderRule{{Path: "/*", Headers: map[string]string{"Cache-Control": "no-store"}}},
+	}
+	config.Merge(Config{
+		Rules:       []Rule{{From: "/old", To: "/config", Status: http.StatusFound}},
+		Headers:     []HeaderRule{{Path: "/*", Headers: map[string]string{"Cache-Control": "no-cache", "X-Frame-Options": "DENY"}}},
+		SPAFallback: true,
+	})
+
+	_, target, ok := config.Route("/old")
+	assert.True(t, ok)
+	assert.Equal(t, "/files", target)
+	assert.Equal(t, http.Header{
+		"Cache-Control":   {"no-store"},
+		"X-Frame-Options": {"DENY"},
+	}, config.HeadersFor("/index.html"))
+	assert.True(t, config.SPAFallback)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+	}{
+		{
+			name: "valid",
+			config: Config{
+				Rules:       []Rule{{From: "/a/:id/*", To: "/b/:id/:splat", Status: 301}, {From: "/*", To: "/index.html", Status: 200}},
+				Headers:     []HeaderRule{{Path: "/*", Headers: map[string]string{"X-Frame-Options": "DENY"}}},
+				SPAFallback: true,
+			},
+		},
+		{
+			name:     "relative path",
+			config:   Config{Rules: []Rule{{From: "old", To: "/new", Status: 301}}},
+			expected: "route 'old': path must start with '/'",
+		},
+		{
+			name:     "splat in the middle",
+			config:   Config{Rules: []Rule{{From: "/a/*/b", To: "/new", Status: 301}}},
+			expected: "'*' is only allowed at the end of the path",
+		},
+		{
+			name:     "partial splat",
+			config:   Config{Rules: []Rule{{From: "/a*", To: "/new", Status: 301}}},
+			expected: "'*' must be a whole path element",
+		},
+		{
+			name:     "query",
+			config:   Config{Rules: []Rule{{From: "/a?b=c", To: "/new", Status: 301}}},
+			expected: "must not contain a query",
+		},
+		{
+			name:     "unknown placeholder",
+			config:   Config{Rules: []Rule{{From: "/a/:id", To: "/b/:slug", Status: 301}}},
+			expected: "target uses ':slug', which is not matched by '/a/:id'",
+		},
+		{
+			name:     "splat without star",
+			config:   Config{Rules: []Rule{{From: "/a", To: "/b/:splat", Status: 301}}},
+			expected: "target uses ':splat'",
+		},
+		{
+			name:     "repeated placeholder",
+			config:   Config{Rules: []Rule{{From: "/:id/:id", To: "/b", Status: 301}}},
+			expected: "placeholder ':id' is used more than once",
+		},
+		{
+			name:     "rewrite to URL",
+			config:   Config{Rules: []Rule{{From: "/api/*", To: "https://api.example.com/:splat", Status: 200}}},
+			expected: "target 'https://api.example.com/:splat' of a 200 rule must be a path in the app",
+		},
+		{
+			name:     "redirect to other scheme",
+			config:   Config{Rules: []Rule{{From: "/a", To: "ftp://example.com", Status: 302}}},
+			expected: "must be a path or an http(s) URL",
+		},
+		{
+			name:     "unsupported status",
+			config:   Config{Rules: []Rule{{From: "/a", To: "/b", Status: 418}}},
+			expected: "unsupported status 418",
+		},
+		{
+			name:     "invalid header name",
+			config:   Config{Headers: []HeaderRule{{Path: "/*", Headers: map[string]string{"X Frame": "DENY"}}}},
+			expected: "headers for '/*': invalid header name 'X Frame'",
+		},
+		{
+			name:     "header value with line break",
+			config:   Config{Headers: []HeaderRule{{Path: "/*", Headers: map[string]string{"X-Test": "a\r\nSet-Cookie: b"}}}},
+			expected: "value of header 'X-Test' contains a line break",
+		},
+		{
+			name:     "no headers",
+			config:   Config{Headers: []HeaderRule{{Path: "/*"}}},
+			expected: "headers for '/*': no headers",
+		},
+		{
+			name:     "too many rules",
+			config:   Config{Rules: make([]Rule, MaxRules+1)},
+			expected: "too many rules: 1001, the limit is 1000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestParseRedirects(t *testing.T) {
+	rules, err := ParseRedirects(strings.NewReader(`
+# Moved sections
+/blog/*      /news/:splat
+/old         /new          302!
+/app/*       /app.html     200
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []Rule{
+		{From: "/blog/*", To: "/news/:splat", Status: 301},
+		{From: "/old", To: "/new", Status: 302, Force: true},
+		{From: "/app/*", To: "/app.html", Status: 200},
+	}, rules)
+}
+
+func TestParseRedirects_Invalid(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{content: "/only-path", expected: "line 1: expected a path and a target"},
+		{content: "\n/a /b abc", expected: "line 2: invalid status 'abc'"},
+		{content: "/a /b 200 Country=us", expected: "line 1: unexpected 'Country=us', conditions are not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			_, err := ParseRedirects(strings.NewReader(tt.content))
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	rules, err := ParseHeaders(strings.NewReader(`/*
+  X-Frame-Options: DENY
+  Content-Security-Policy: default-src 'self'
+  Content-Security-Policy: img-src *
+
+# Long-lived assets
+/assets/*
+	Cache-Control: public, max-age=31536000, immutable
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []HeaderRule{
+		{Path: "/*", Headers: map[string]string{"X-Frame-Options": "DENY", "Content-Security-Policy": "default-src 'self', img-src *"}},
+		{Path: "/assets/*", Headers: map[string]string{"Cache-Control": "public, max-age=31536000, immutable"}},
+	}, rules)
+}
+
+func TestParseHeaders_Invalid(t *testing.T) {
+	_, err := ParseHeaders(strings.NewReader("  X-Frame-Options: DENY\n"))
+	require.EqualError(t, err, "line 1: header before any path")
+
+	_, err = ParseHeaders(strings.NewReader("/*\n  X-Frame-Options\n"))
+	require.EqualError(t, err, "line 2: expected 'Name: value'")
+}
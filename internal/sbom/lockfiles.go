@@ -0,0 +1,213 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Lockfiles are the npm lockfiles dependencies are read from, in the order they
+// are looked up
+var Lockfiles = []string{"npm-shrinkwrap.json", "package-lock.json", "yarn.lock"}
+
+// NPMDependencies reads the npm packages locked by the lockfiles in dir. It
+// returns an empty list if dir has no lockfile.
+func NPMDependencies(dir string) ([]Dependency, error) {
+	deps := map[string]Dependency{}
+	for _, name := range Lockfiles {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var locked []Dependency
+		if name == "yarn.lock" {
+			locked, err = parseYarnLock(data)
+		} else {
+			locked, err = parsePackageLock(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		for _, dep := range locked {
+			addDependency(deps, dep)
+		}
+	}
+
+	result := make([]Dependency, 0, len(deps))
+	for _, dep := range deps {
+		result = append(result, dep)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+// addDependency records dep, which is only a dev dependency if every lockfile
+// entry for it is.
+func addDependency(deps map[string]Dependency, dep Dependency) {
+	key := dep.Name + "@" + dep.Version
+	if existing, ok := deps[key]; ok {
+		dep.Dev = dep.Dev && existing.Dev
+		if dep.License == "" {
+			dep.License = existing.License
+		}
+	}
+	deps[key] = dep
+}
+
+type packageLock struct {
+	LockfileVersion int                           `json:"lockfileVersion"`
+	Packages        map[string]packageLockPackage `json:"packages"`
+	Dependencies    map[string]packageLockDep     `json:"dependencies"`
+}
+
+type packageLockPackage struct {
+	Name    string          `json:"name"`
+	Version string          `json:"version"`
+	License json.RawMessage `json:"license"`
+	Dev     bool            `json:"dev"`
+	Link    bool            `json:"link"`
+}
+
+type packageLockDep struct {
+	Version      string                    `json:"version"`
+	Dev          bool                      `json:"dev"`
+	Dependencies map[string]packageLockDep `json:"dependencies"`
+}
+
+// parsePackageLock reads a package-lock.json or npm-shrinkwrap.json. Version 2
+// and 3 lockfiles list every package under "packages", version 1 lockfiles
+// nest them under "dependencies".
+func parsePackageLock(data []byte) ([]Dependency, error) {
+	lock := packageLock{}
+	err := json.Unmarshal(data, &lock)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []Dependency{}
+	if lock.Packages != nil {
+		for path, pkg := range lock.Packages {
+			// The root package and workspace links are the project itself.
+			if path == "" || pkg.Link || pkg.Version == "" {
+				continue
+			}
+			name := pkg.Name
+			if name == "" {
+				i := strings.LastIndex(path, "node_modules/")
+				if i < 0 {
+					continue
+				}
+				name = path[i+len("node_modules/"):]
+			}
+			deps = append(deps, Dependency{Name: name, Version: pkg.Version, License: packageLicense(pkg.License), Dev: pkg.Dev})
+		}
+		return deps, nil
+	}
+
+	var walk func(map[string]packageLockDep)
+	walk = func(nested map[string]packageLockDep) {
+		for name, dep := range nested {
+			// Version 1 lockfiles record non-registry sources, such as
+			// "file:../lib", in place of a version.
+			if dep.Version != "" && !strings.Contains(dep.Version, ":") {
+				deps = append(deps, Dependency{Name: name, Version: dep.Version, Dev: dep.Dev})
+			}
+			walk(dep.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	return deps, nil
+}
+
+// packageLicense reads the license of a lockfile package, which is an SPDX
+// expression or, in old packages, an object with a type.
+func packageLicense(raw json.RawMessage) string {
+	var license string
+	if json.Unmarshal(raw, &license) == nil {
+		return license
+	}
+	var typed struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(raw, &typed) == nil {
+		return typed.Type
+	}
+	return ""
+}
+
+// parseYarnLock reads a yarn.lock of yarn 1, or of yarn 2 and later, which
+// is YAML with the same layout. Each entry starts with an unindented line of
+// the descriptors it resolves and has an indented version field.
+func parseYarnLock(data []byte) ([]Dependency, error) {
+	deps := []Dependency{}
+	name := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") {
+			name = yarnEntryName(strings.TrimSuffix(trimmed, ":"))
+			continue
+		}
+
+		if name == "" || strings.HasPrefix(line, "    ") {
+			continue
+		}
+		field, value, ok := strings.Cut(trimmed, " ")
+		if !ok || strings.TrimSuffix(field, ":") != "version" {
+			continue
+		}
+		version := strings.Trim(strings.TrimSpace(value), `"`)
+		if version != "" {
+			deps = append(deps, Dependency{Name: name, Version: version})
+		}
+		name = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+// yarnEntryName returns the package name of a yarn.lock entry, such as
+// `"@babel/core@^7.0.0", "@babel/core@npm:^7.1.0"`. It returns "" for the
+// lockfile metadata and for entries that are not from the registry, such as
+// workspaces.
+func yarnEntryName(header string) string {
+	descriptor, _, _ := strings.Cut(header, ",")
+	descriptor = strings.Trim(strings.TrimSpace(descriptor), `"`)
+
+	// The name ends at the first "@" past the scope, since the descriptors of
+	// patched packages in yarn 2 nest the original descriptor.
+	at := strings.Index(descriptor[min(1, len(descriptor)):], "@")
+	if at < 0 {
+		return ""
+	}
+	name, spec := descriptor[:at+1], descriptor[at+2:]
+
+	if protocol, _, ok := strings.Cut(spec, ":"); ok && protocol != "npm" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,272 @@
+// Package sbom inventories a bundle and the npm packages of the project it was
+// built from as an SPDX 2.3 software bill of materials.
+//
+// See https://spdx.github.io/spdx-spec/v2.3/
+package sbom
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// SPDXVersion is the version of the SPDX specification documents follow
+	SPDXVersion = "SPDX-2.3"
+	// DataLicense is the license of every SPDX document
+	DataLicense = "CC0-1.0"
+	// NamespacePrefix is prepended to the name and bundle digest of a document
+	// to form its unique namespace
+	NamespacePrefix = "https://github.com/github/gh-runtime-cli/spdx/"
+
+	// PurposeApplication is the primary purpose of the bundle package
+	PurposeApplication = "APPLICATION"
+	// PurposeLibrary is the primary purpose of the packages the bundle depends on
+	PurposeLibrary = "LIBRARY"
+
+	documentID  = "SPDXRef-DOCUMENT"
+	bundleID    = "SPDXRef-Bundle"
+	noAssertion = "NOASSERTION"
+)
+
+// Document is an SPDX document
+type Document struct {
+	SPDXVersion       string         `json:"spdxVersion"`
+	DataLicense       string         `json:"dataLicense"`
+	SPDXID            string         `json:"SPDXID"`
+	Name              string         `json:"name"`
+	DocumentNamespace string         `json:"documentNamespace"`
+	CreationInfo      CreationInfo   `json:"creationInfo"`
+	Packages          []Package      `json:"packages"`
+	Files             []File         `json:"files"`
+	Relationships     []Relationship `json:"relationships"`
+}
+
+// CreationInfo records who created a document and when
+type CreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+// Package is an SPDX package: the bundle itself or a dependency of it
+type Package struct {
+	Name                    string                   `json:"name"`
+	SPDXID                  string                   `json:"SPDXID"`
+	VersionInfo             string                   `json:"versionInfo,omitempty"`
+	DownloadLocation        string                   `json:"downloadLocation"`
+	FilesAnalyzed           bool                     `json:"filesAnalyzed"`
+	PackageVerificationCode *PackageVerificationCode `json:"packageVerificationCode,omitempty"`
+	Checksums               []Checksum               `json:"checksums,omitempty"`
+	LicenseDeclared         string                   `json:"licenseDeclared,omitempty"`
+	ExternalRefs            []ExternalRef            `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose   string                   `json:"primaryPackagePurpose,omitempty"`
+}
+
+// PackageVerificationCode identifies the set of files of a package
+type PackageVerificationCode struct {
+	Value string `json:"packageVerificationCodeValue"`
+}
+
+// File is a file of the bundle
+type File struct {
+	FileName  string     `json:"fileName"`
+	SPDXID    string     `json:"SPDXID"`
+	Checksums []Checksum `json:"checksums"`
+}
+
+// Checksum is a digest of a file or package
+type Checksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+// ExternalRef identifies a package outside of the document, such as by its
+// package URL
+type ExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+// Relationship relates two elements of a document
+type Relationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// BundleFile is a file of a bundle and its digests
+type BundleFile struct {
+	Path   string
+	SHA1   string
+	SHA256 string
+}
+
+// Dependency is a package a bundle was built from
+type Dependency struct {
+	Name    string
+	Version string
+	License string
+	// Dev is set for packages that are only needed to build the bundle
+	Dev bool
+}
+
+// Build describes the bundle a document is created for
+type Build struct {
+	// Name names the document, usually after the app
+	Name         string
+	BundleName   string
+	BundleDigest string
+	// Version is the revision of the bundle, usually a commit SHA
+	Version      string
+	Files        []BundleFile
+	Dependencies []Dependency
+	Creator      string
+	Created      time.Time
+}
+
+// New creates the SPDX document of a bundle. The bundle is described by the
+// document, contains its files and depends on its dependencies.
+func New(build Build) Document {
+	doc := Document{
+		SPDXVersion:       SPDXVersion,
+		DataLicense:       DataLicense,
+		SPDXID:            documentID,
+		Name:              build.Name,
+		DocumentNamespace: NamespacePrefix + url.PathEscape(build.Name) + "-" + build.BundleDigest,
+		CreationInfo: CreationInfo{
+			Created:  build.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + build.Creator},
+		},
+		Packages:      []Package{},
+		Files:         []File{},
+		Relationships: []Relationship{{Element: documentID, Type: "DESCRIBES", Related: bundleID}},
+	}
+
+	files := append([]BundleFile{}, build.Files...)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	for i, file := range files {
+		id := fmt.Sprintf("SPDXRef-File-%d", i+1)
+		doc.Files = append(doc.Files, File{
+			FileName: "./" + file.Path,
+			SPDXID:   id,
+			Checksums: []Checksum{
+				{Algorithm: "SHA1", Value: file.SHA1},
+				{Algorithm: "SHA256", Value: file.SHA256},
+			},
+		})
+		doc.Relationships = append(doc.Relationships, Relationship{Element: bundleID, Type: "CONTAINS", Related: id})
+	}
+
+	doc.Packages = append(doc.Packages, Package{
+		Name:                    build.BundleName,
+		SPDXID:                  bundleID,
+		VersionInfo:             build.Version,
+		DownloadLocation:        noAssertion,
+		FilesAnalyzed:           true,
+		PackageVerificationCode: &PackageVerificationCode{Value: verificationCode(files)},
+		Checksums:               []Checksum{{Algorithm: "SHA256", Value: build.BundleDigest}},
+		PrimaryPackagePurpose:   PurposeApplication,
+	})
+
+	for i, dep := range build.Dependencies {
+		id := fmt.Sprintf("SPDXRef-Package-npm-%d", i+1)
+		doc.Packages = append(doc.Packages, Package{
+			Name:                  dep.Name,
+			SPDXID:                id,
+			VersionInfo:           dep.Version,
+			DownloadLocation:      noAssertion,
+			LicenseDeclared:       dep.License,
+			ExternalRefs:          []ExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: NPMPackageURL(dep.Name, dep.Version)}},
+			PrimaryPackagePurpose: PurposeLibrary,
+		})
+		if dep.Dev {
+			doc.Relationships = append(doc.Relationships, Relationship{Element: id, Type: "DEV_DEPENDENCY_OF", Related: bundleID})
+		} else {
+			doc.Relationships = append(doc.Relationships, Relationship{Element: bundleID, Type: "DEPENDS_ON", Related: id})
+		}
+	}
+
+	return doc
+}
+
+// Libraries returns the number of packages the bundle depends on, whatever
+// the order of the packages
+func (d Document) Libraries() int {
+	n := 0
+	for _, pkg := range d.Packages {
+		if pkg.PrimaryPackagePurpose == PurposeLibrary {
+			n++
+		}
+	}
+	return n
+}
+
+// verificationCode is the SHA1 of the sorted SHA1 digests of every file of a
+// package.
+func verificationCode(files []BundleFile) string {
+	digests := make([]string, 0, len(files))
+	for _, file := range files {
+		digests = append(digests, file.SHA1)
+	}
+	sort.Strings(digests)
+	sum := sha1.Sum([]byte(strings.Join(digests, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+// NPMPackageURL returns the package URL of an npm package.
+// See https://github.com/package-url/purl-spec
+func NPMPackageURL(name, version string) string {
+	name = strings.Replace(name, "@", "%40", 1)
+	return "pkg:npm/" + name + "@" + url.PathEscape(version)
+}
+
+// BundleFiles reads the files of a zip bundle and their digests. Directories
+// are skipped.
+func BundleFiles(zipPath string) ([]BundleFile, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	files := []BundleFile{}
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		file, err := digestZipFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("reading '%s': %w", f.Name, err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func digestZipFile(f *zip.File) (BundleFile, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return BundleFile{}, err
+	}
+	defer rc.Close()
+
+	sha1Hash, sha256Hash := sha1.New(), sha256.New()
+	_, err = io.Copy(io.MultiWriter(sha1Hash, sha256Hash), rc)
+	if err != nil {
+		return BundleFile{}, err
+	}
+	return BundleFile{Path: f.Name, SHA1: hexSum(sha1Hash), SHA256: hexSum(sha256Hash)}, nil
+}
+
+func hexSum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
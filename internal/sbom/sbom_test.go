@@ -0,0 +1,218 @@
+package sbom
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	doc := New(Build{
+		Name:         "my-app",
+		BundleName:   "bundle.zip",
+		BundleDigest: "abc123",
+		Version:      "0123456789",
+		Files: []BundleFile{
+			{Path: "index.html", SHA1: "bbbb", SHA256: "2222"},
+			{Path: "assets/app.js", SHA1: "aaaa", SHA256: "1111"},
+		},
+		Dependencies: []Dependency{
+			{Name: "@vitejs/plugin-react", Version: "4.3.1", License: "MIT", Dev: true},
+			{Name: "react", Version: "18.3.1", License: "MIT"},
+		},
+		Creator: "gh-runtime-cli-1.0.0",
+		Created: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	})
+
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, "SPDXRef-DOCUMENT", doc.SPDXID)
+	assert.Equal(t, "https://github.com/github/gh-runtime-cli/spdx/my-app-abc123", doc.DocumentNamespace)
+	assert.Equal(t, "2024-05-01T12:00:00Z", doc.CreationInfo.Created)
+	assert.Equal(t, []string{"Tool: gh-runtime-cli-1.0.0"}, doc.CreationInfo.Creators)
+
+	require.Len(t, doc.Files, 2)
+	assert.Equal(t, "./assets/app.js", doc.Files[0].FileName)
+	assert.Equal(t, "./index.html", doc.Files[1].FileName)
+
+	require.Len(t, doc.Packages, 3)
+	assert.Equal(t, 2, doc.Libraries())
+	bundle := doc.Packages[0]
+	assert.Equal(t, "bundle.zip", bundle.Name)
+	assert.Equal(t, "0123456789", bundle.VersionInfo)
+	assert.True(t, bundle.FilesAnalyzed)
+	// sha1("aaaabbbb")
+	assert.Equal(t, "c55e94247fbfc4f11842fc3bd979e5beb5ed1080", bundle.PackageVerificationCode.Value)
+	assert.Equal(t, "pkg:npm/%40vitejs/plugin-react@4.3.1", doc.Packages[1].ExternalRefs[0].Locator)
+
+	assert.Equal(t, []Relationship{
+		{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: "SPDXRef-Bundle"},
+		{Element: "SPDXRef-Bundle", Type: "CONTAINS", Related: "SPDXRef-File-1"},
+		{Element: "SPDXRef-Bundle", Type: "CONTAINS", Related: "SPDXRef-File-2"},
+		{Element: "SPDXRef-Package-npm-1", Type: "DEV_DEPENDENCY_OF", Related: "SPDXRef-Bundle"},
+		{Element: "SPDXRef-Bundle", Type: "DEPENDS_ON", Related: "SPDXRef-Package-npm-2"},
+	}, doc.Relationships)
+}
+
+func TestDocument_Libraries(t *testing.T) {
+	doc := Document{Packages: []Package{
+		{Name: "react", PrimaryPackagePurpose: PurposeLibrary},
+		{Name: "bundle.zip", PrimaryPackagePurpose: PurposeApplication},
+		{Name: "vite", PrimaryPackagePurpose: PurposeLibrary},
+	}}
+	assert.Equal(t, 2, doc.Libraries())
+	assert.Equal(t, 0, Document{}.Libraries())
+}
+
+func TestBundleFiles(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "bundle.zip")
+	f, err := os.Create(zipPath)
+	require.NoError(t, err)
+	w := zip.NewWriter(f)
+	_, err = w.Create("assets/")
+	require.NoError(t, err)
+	fw, err := w.Create("index.html")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	files, err := BundleFiles(zipPath)
+	require.NoError(t, err)
+	assert.Equal(t, []BundleFile{{
+		Path:   "index.html",
+		SHA1:   "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+		SHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}}, files)
+}
+
+func TestNPMDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []Dependency
+	}{
+		{
+			name:     "no lockfile",
+			files:    map[string]string{"package.json": `{}`},
+			expected: []Dependency{},
+		},
+		{
+			name: "package-lock.json v3",
+			files: map[string]string{"package-lock.json": `{
+				"lockfileVersion": 3,
+				"packages": {
+					"": {"name": "site", "version": "1.0.0"},
+					"node_modules/react": {"version": "18.3.1", "license": "MIT"},
+					"node_modules/@types/react": {"version": "18.3.3", "license": "MIT", "dev": true},
+					"node_modules/a/node_modules/react": {"version": "17.0.2", "license": "MIT"},
+					"node_modules/string-width-cjs": {"name": "string-width", "version": "4.2.3"},
+					"node_modules/shared": {"resolved": "packages/shared", "link": true},
+					"packages/shared": {"version": "0.0.1"}
+				}
+			}`},
+			expected: []Dependency{
+				{Name: "@types/react", Version: "18.3.3", License: "MIT", Dev: true},
+				{Name: "react", Version: "17.0.2", License: "MIT"},
+				{Name: "react", Version: "18.3.1", License: "MIT"},
+				{Name: "string-width", Version: "4.2.3"},
+			},
+		},
+		{
+			name: "package-lock.json v1",
+			files: map[string]string{"package-lock.json": `{
+				"lockfileVersion": 1,
+				"dependencies": {
+					"left-pad": {"version": "1.3.0", "dev": true},
+					"local": {"version": "file:../local"},
+					"a": {"version": "1.0.0", "dependencies": {"b": {"version": "2.0.0"}}}
+				}
+			}`},
+			expected: []Dependency{
+				{Name: "a", Version: "1.0.0"},
+				{Name: "b", Version: "2.0.0"},
+				{Name: "left-pad", Version: "1.3.0", Dev: true},
+			},
+		},
+		{
+			name: "yarn.lock v1",
+			files: map[string]string{"yarn.lock": `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@babel/code-frame@^7.0.0", "@babel/code-frame@^7.10.4":
+  version "7.12.13"
+  resolved "https://registry.yarnpkg.com/@babel/code-frame/-/code-frame-7.12.13.tgz"
+  dependencies:
+    "@babel/highlight" "^7.10.4"
+
+lodash@^4.17.21:
+  version "4.17.21"
+`},
+			expected: []Dependency{
+				{Name: "@babel/code-frame", Version: "7.12.13"},
+				{Name: "lodash", Version: "4.17.21"},
+			},
+		},
+		{
+			name: "yarn.lock v2",
+			files: map[string]string{"yarn.lock": `__metadata:
+  version: 8
+  cacheKey: 10c0
+
+"lodash@npm:^4.17.21":
+  version: 4.17.21
+  resolution: "lodash@npm:4.17.21"
+  dependencies:
+    foo: "npm:1.0.0"
+
+"resolve@patch:resolve@npm%3A^1.22.0#~builtin<compat/resolve>":
+  version: 1.22.8
+  resolution: "resolve@patch:resolve@npm%3A1.22.8#~builtin<compat/resolve>::version=1.22.8"
+
+"site@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "site@workspace:."
+`},
+			expected: []Dependency{
+				{Name: "lodash", Version: "4.17.21"},
+			},
+		},
+		{
+			name: "package-lock.json and yarn.lock",
+			files: map[string]string{
+				"package-lock.json": `{"lockfileVersion": 3, "packages": {"node_modules/lodash": {"version": "4.17.21", "license": "MIT", "dev": true}}}`,
+				"yarn.lock":         "lodash@^4.17.21:\n  version \"4.17.21\"\n",
+			},
+			expected: []Dependency{
+				{Name: "lodash", Version: "4.17.21", License: "MIT"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+			}
+
+			deps, err := NPMDependencies(dir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, deps)
+		})
+	}
+}
+
+func TestNPMDependencies_InvalidLockfile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte("{"), 0644))
+
+	_, err := NPMDependencies(dir)
+	require.ErrorContains(t, err, "package-lock.json")
+}
@@ -0,0 +1,297 @@
+// Package secrets scans files for secrets that should never be deployed, such as
+// access tokens, private keys and environment files. Only high-confidence
+// patterns are matched so that findings can block a deploy.
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// binarySniffLength is how much of a file is checked for NUL bytes to
+	// decide whether it is binary, as git does
+	binarySniffLength = 8000
+	// maxLineLength is how much of a line is matched at once. Longer lines,
+	// as in minified files, are matched in pieces
+	maxLineLength = 1 << 20
+	// lineOverlap is how much of the previous piece of a long line is matched
+	// again with the next one, so that secrets across pieces are found. It is
+	// longer than anything the patterns match
+	lineOverlap = 256
+)
+
+// Rule is a kind of secret
+type Rule struct {
+	// ID identifies the rule in suppressions, e.g. "github-token"
+	ID          string
+	Description string
+	// pattern matches the secret in file content. Rules without a pattern
+	// match file names.
+	pattern *regexp.Regexp
+	// matchName reports whether a file name is a secret.
+	matchName func(name string) bool
+	// redact hides the matched text in findings, for patterns that match the
+	// secret itself rather than a marker around it.
+	redact bool
+}
+
+// Rules are the rules files are scanned with
+var Rules = []Rule{
+	{
+		ID:          "github-token",
+		Description: "GitHub token",
+		pattern:     regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`),
+		redact:      true,
+	},
+	{
+		ID:          "aws-access-key-id",
+		Description: "AWS access key ID",
+		pattern:     regexp.MustCompile(`\b(?:AKIA|ASIA)[A-Z0-9]{16}\b`),
+		redact:      true,
+	},
+	{
+		ID:          "aws-secret-access-key",
+		Description: "AWS secret access key",
+		pattern:     regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+]{40}\b`),
+		redact:      true,
+	},
+	{
+		ID:          "private-key",
+		Description: "private key",
+		pattern:     regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		ID:          "env-file",
+		Description: "environment file",
+		matchName:   IsEnvFile,
+	},
+}
+
+// envFileTemplates are suffixes of environment files that document variables
+// rather than hold them
+var envFileTemplates = []string{".example", ".sample", ".template", ".dist", ".defaults"}
+
+// IsEnvFile reports whether name is an environment file such as .env or
+// .env.production.local. Templates such as .env.example are not.
+func IsEnvFile(name string) bool {
+	base := path.Base(name)
+	if base != ".env" && !strings.HasPrefix(base, ".env.") {
+		return false
+	}
+	for _, suffix := range envFileTemplates {
+		if strings.HasSuffix(base, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Finding is a possible secret in a file
+type Finding struct {
+	Path string
+	// Line is the 1-based line of the secret, or 0 if the whole file is one
+	Line   int
+	RuleID string
+	// Description describes the secret, with the matched text redacted
+	Description string
+}
+
+func (f Finding) String() string {
+	location := f.Path
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d", f.Path, f.Line)
+	}
+	return fmt.Sprintf("%s: %s (%s)", location, f.Description, f.RuleID)
+}
+
+// Suppression ignores findings in files matching a glob
+type Suppression struct {
+	// Path is a glob of slash-separated paths, where "**" matches any number
+	// of directories. Globs without a slash match the file name anywhere.
+	Path string
+	// Rule limits the suppression to one rule. Every rule is suppressed if
+	// it is empty.
+	Rule string
+}
+
+// Scanner scans files for secrets
+type Scanner struct {
+	suppressions []Suppression
+}
+
+// New returns a scanner that ignores the given suppressions
+func New(suppressions []Suppression) (*Scanner, error) {
+	for _, s := range suppressions {
+		if s.Path == "" {
+			return nil, fmt.Errorf("suppression has no path")
+		}
+		if _, err := path.Match(s.Path, ""); err != nil {
+			return nil, fmt.Errorf("invalid suppression path '%s': %w", s.Path, err)
+		}
+		if s.Rule != "" && !knownRule(s.Rule) {
+			return nil, fmt.Errorf("suppression of '%s' has unknown rule '%s'", s.Path, s.Rule)
+		}
+	}
+	return &Scanner{suppressions: suppressions}, nil
+}
+
+func knownRule(id string) bool {
+	for _, rule := range Rules {
+		if rule.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan reads the file at name from r and returns the secrets found in it that
+// are not suppressed. Binary files are only checked by name. Text files are
+// read line by line, so patterns do not match across lines.
+func (s *Scanner) Scan(name string, r io.Reader) ([]Finding, error) {
+	reader := bufio.NewReaderSize(r, maxLineLength)
+	head, err := reader.Peek(binarySniffLength)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	binary := bytes.IndexByte(head, 0) >= 0
+
+	// Findings are grouped by rule, in the order of Rules.
+	ruleFindings := make([][]Finding, len(Rules))
+	contentRules := []int{}
+	for i, rule := range Rules {
+		if s.suppressed(name, rule.ID) {
+			continue
+		}
+
+		if rule.matchName != nil {
+			if rule.matchName(name) {
+				ruleFindings[i] = []Finding{{Path: name, RuleID: rule.ID, Description: rule.Description}}
+			}
+			continue
+		}
+		if !binary {
+			contentRules = append(contentRules, i)
+		}
+	}
+
+	if len(contentRules) > 0 {
+		err = scanLines(name, reader, contentRules, ruleFindings)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	findings := []Finding{}
+	for _, f := range ruleFindings {
+		findings = append(findings, f...)
+	}
+	return findings, nil
+}
+
+// scanLines matches the content rules at the given indexes of Rules against
+// every line read from reader, adding what they find to ruleFindings.
+func scanLines(name string, reader *bufio.Reader, rules []int, ruleFindings [][]Finding) error {
+	line := 1
+	// overlap is the end of the previous piece of the current line.
+	var overlap []byte
+	for {
+		piece, err := reader.ReadSlice('\n')
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return err
+		}
+
+		text := piece
+		if len(overlap) > 0 {
+			text = append(overlap, piece...)
+		}
+		for _, i := range rules {
+			rule := Rules[i]
+			for _, loc := range rule.pattern.FindAllIndex(text, -1) {
+				// Matches within the overlap were found in the previous piece.
+				if loc[1] <= len(overlap) {
+					continue
+				}
+				match := string(text[loc[0]:loc[1]])
+				description := fmt.Sprintf("%s %s", rule.Description, match)
+				if rule.redact {
+					description = fmt.Sprintf("%s %s", rule.Description, Redact(match))
+				}
+				ruleFindings[i] = append(ruleFindings[i], Finding{
+					Path:        name,
+					Line:        line,
+					RuleID:      rule.ID,
+					Description: description,
+				})
+			}
+		}
+
+		switch err {
+		case io.EOF:
+			return nil
+		case bufio.ErrBufferFull:
+			overlap = bytes.Clone(text[max(len(text)-lineOverlap, 0):])
+		default:
+			overlap = nil
+			line++
+		}
+	}
+}
+
+func (s *Scanner) suppressed(name, ruleID string) bool {
+	for _, suppression := range s.suppressions {
+		if suppression.Rule != "" && suppression.Rule != ruleID {
+			continue
+		}
+		if MatchPath(suppression.Path, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath reports whether the slash-separated path name matches pattern. A
+// "**" element matches any number of directories, and a pattern without a
+// slash is matched against the last element of name.
+func MatchPath(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchElements(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElements(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchElements(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// Redact hides all but the first four characters of a secret, which for
+// tokens identify their kind.
+func Redact(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", 8)
+}
@@ -0,0 +1,229 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Secrets are assembled at runtime so that this file does not trip secret
+// scanners itself.
+var (
+	githubToken    = "ghp_" + strings.Repeat("a1B2", 9)
+	githubPAT      = "github_pat_" + strings.Repeat("x", 82)
+	awsAccessKeyID = "AKIA" + "IOSFODNN7EXAMPLE"
+	awsSecretKey   = "wJalrXUtnFEMI/K7MDENG/" + "bPxRfiCYEXAMPLEKEY"
+	pemHeader      = "-----BEGIN " + "RSA PRIVATE KEY-----"
+)
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		content  string
+		expected []Finding
+	}{
+		{
+			name:     "clean file",
+			path:     "index.html",
+			content:  "<html><body>ghp_short AKIA123</body></html>",
+			expected: []Finding{},
+		},
+		{
+			name:    "GitHub token in built JS",
+			path:    "assets/index-abc123.js",
+			content: "const a=1;\nfetch(u,{headers:{Authorization:\"token " + githubToken + "\"}})",
+			expected: []Finding{
+				{Path: "assets/index-abc123.js", Line: 2, RuleID: "github-token", Description: "GitHub token ghp_********"},
+			},
+		},
+		{
+			name:    "fine-grained GitHub token",
+			path:    "app.js",
+			content: "t='" + githubPAT + "'",
+			expected: []Finding{
+				{Path: "app.js", Line: 1, RuleID: "github-token", Description: "GitHub token gith********"},
+			},
+		},
+		{
+			name:    "AWS keys",
+			path:    "config.js",
+			content: "export default {\n  accessKeyId: '" + awsAccessKeyID + "',\n  aws_secret_access_key: '" + awsSecretKey + "'\n}",
+			expected: []Finding{
+				{Path: "config.js", Line: 2, RuleID: "aws-access-key-id", Description: "AWS access key ID AKIA********"},
+				{Path: "config.js", Line: 3, RuleID: "aws-secret-access-key", Description: "AWS secret access key aws_********"},
+			},
+		},
+		{
+			name:    "private key",
+			path:    "certs/server.pem",
+			content: pemHeader + "\nMIIEpAIBAAKCAQEA\n-----END RSA PRIVATE KEY-----\n",
+			expected: []Finding{
+				{Path: "certs/server.pem", Line: 1, RuleID: "private-key", Description: "private key " + pemHeader},
+			},
+		},
+		{
+			name:     "public key",
+			path:     "key.pub",
+			content:  "-----BEGIN PUBLIC KEY-----\nMCowBQYDK2VwAyEA\n-----END PUBLIC KEY-----\n",
+			expected: []Finding{},
+		},
+		{
+			name:    "env file",
+			path:    ".env",
+			content: "API_URL=https://example.com\n",
+			expected: []Finding{
+				{Path: ".env", RuleID: "env-file", Description: "environment file"},
+			},
+		},
+		{
+			name:    "nested env file",
+			path:    "config/.env.production.local",
+			content: "",
+			expected: []Finding{
+				{Path: "config/.env.production.local", RuleID: "env-file", Description: "environment file"},
+			},
+		},
+		{
+			name:     "env template",
+			path:     ".env.example",
+			content:  "API_KEY=\n",
+			expected: []Finding{},
+		},
+		{
+			name:     "binary file",
+			path:     "image.png",
+			content:  "\x89PNG\x00\x00" + githubToken,
+			expected: []Finding{},
+		},
+	}
+
+	scanner, err := New(nil)
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := scanner.Scan(tt.path, strings.NewReader(tt.content))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, findings)
+		})
+	}
+}
+
+func TestScan_LongLines(t *testing.T) {
+	// The token starts just before the end of the first piece of the line.
+	content := strings.Repeat("a", maxLineLength-10) + " " + githubToken + " " + githubToken + "\n" + githubToken
+
+	scanner, err := New(nil)
+	require.NoError(t, err)
+	findings, err := scanner.Scan("app.min.js", strings.NewReader(content))
+	require.NoError(t, err)
+
+	lines := []int{}
+	for _, f := range findings {
+		lines = append(lines, f.Line)
+	}
+	assert.Equal(t, []int{1, 1, 2}, lines)
+}
+
+func TestScan_Suppressions(t *testing.T) {
+	tests := []struct {
+		name         string
+		suppressions []Suppression
+		path         string
+		expected     []string
+	}{
+		{
+			name:     "no suppressions",
+			path:     "docs/fixtures/keys.txt",
+			expected: []string{"github-token", "private-key"},
+		},
+		{
+			name:         "every rule",
+			suppressions: []Suppression{{Path: "docs/**"}},
+			path:         "docs/fixtures/keys.txt",
+			expected:     []string{},
+		},
+		{
+			name:         "one rule",
+			suppressions: []Suppression{{Path: "**/fixtures/*.txt", Rule: "private-key"}},
+			path:         "docs/fixtures/keys.txt",
+			expected:     []string{"github-token"},
+		},
+		{
+			name:         "file name",
+			suppressions: []Suppression{{Path: "keys.*"}},
+			path:         "docs/fixtures/keys.txt",
+			expected:     []string{},
+		},
+		{
+			name:         "other path",
+			suppressions: []Suppression{{Path: "assets/*"}},
+			path:         "docs/fixtures/keys.txt",
+			expected:     []string{"github-token", "private-key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner, err := New(tt.suppressions)
+			require.NoError(t, err)
+
+			findings, err := scanner.Scan(tt.path, strings.NewReader(githubToken+"\n"+pemHeader))
+			require.NoError(t, err)
+			rules := []string{}
+			for _, finding := range findings {
+				rules = append(rules, finding.RuleID)
+			}
+			assert.Equal(t, tt.expected, rules)
+		})
+	}
+}
+
+func TestNew_InvalidSuppressions(t *testing.T) {
+	tests := []struct {
+		name         string
+		suppressions []Suppression
+		expected     string
+	}{
+		{name: "no path", suppressions: []Suppression{{Rule: "env-file"}}, expected: "suppression has no path"},
+		{name: "bad glob", suppressions: []Suppression{{Path: "assets/["}}, expected: "invalid suppression path 'assets/['"},
+		{name: "unknown rule", suppressions: []Suppression{{Path: ".env", Rule: "dotenv"}}, expected: "unknown rule 'dotenv'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.suppressions)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.map", "assets/app.js.map", true},
+		{"assets/*.map", "assets/app.js.map", true},
+		{"assets/*.map", "assets/js/app.js.map", false},
+		{"assets/**", "assets/js/app.js", true},
+		{"**/fixtures/**", "a/b/fixtures/c/d.txt", true},
+		{"**/fixtures/**", "fixtures/d.txt", true},
+		{"**/*.pem", "server.pem", true},
+		{"docs/*", "other/docs/a", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MatchPath(tt.pattern, tt.name))
+		})
+	}
+}
+
+func TestFindingString(t *testing.T) {
+	assert.Equal(t, "app.js:3: GitHub token ghp_******** (github-token)", Finding{Path: "app.js", Line: 3, RuleID: "github-token", Description: "GitHub token ghp_********"}.String())
+	assert.Equal(t, ".env: environment file (env-file)", Finding{Path: ".env", RuleID: "env-file", Description: "environment file"}.String())
+}